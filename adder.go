@@ -0,0 +1,47 @@
+package ctxcache
+
+import (
+	"math/rand/v2"
+	"sync/atomic"
+)
+
+// adderCells is the number of cells an adder stripes its count over once
+// contended.
+const adderCells = 16
+
+// adder is a counter for the hit path. Adds go to base until two of them
+// race on it, then to cells on separate cache lines picked at random, so
+// that concurrent hits of one cache do not all write the same word.
+type adder struct {
+	base  atomic.Uint64
+	cells atomic.Pointer[[adderCells]adderCell]
+}
+
+type adderCell struct {
+	n atomic.Uint64
+	_ [56]byte
+}
+
+func (a *adder) Add(n uint64) {
+	cells := a.cells.Load()
+	if cells == nil {
+		old := a.base.Load()
+		if a.base.CompareAndSwap(old, old+n) {
+			return
+		}
+		a.cells.CompareAndSwap(nil, new([adderCells]adderCell))
+		cells = a.cells.Load()
+	}
+	cells[rand.Uint32()%adderCells].n.Add(n)
+}
+
+// Load returns the sum of the adds, exact once they are done.
+func (a *adder) Load() uint64 {
+	n := a.base.Load()
+	if cells := a.cells.Load(); cells != nil {
+		for i := range cells {
+			n += cells[i].n.Load()
+		}
+	}
+	return n
+}
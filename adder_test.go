@@ -0,0 +1,29 @@
+package ctxcache
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestAdder(t *testing.T) {
+	var a adder
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 10000 {
+				a.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := a.Load(); n != 80000 {
+		t.Fatalf("count = %d", n)
+	}
+	a.cells.CompareAndSwap(nil, new([adderCells]adderCell))
+	a.Add(2)
+	if n := a.Load(); n != 80002 {
+		t.Fatalf("count = %d after striping", n)
+	}
+}
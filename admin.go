@@ -0,0 +1,72 @@
+package ctxcache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// AdminOption configures the handler returned by AdminHandler.
+type AdminOption func(*adminHandler)
+
+// errBadKey wraps the errors of parsing the key of a DELETE request.
+var errBadKey = errors.New("invalid key")
+
+type adminHandler struct {
+	invalidators map[FuncID]func(ctx context.Context, key string) error
+}
+
+// Invalidatable lets DELETE requests to the admin handler drop keys of the
+// shared store serving ctxKey. parse converts the key query parameter to K.
+func Invalidatable[K comparable, V any](ctxKey FuncID, store Store[K, V], parse func(string) (K, error)) AdminOption {
+	return func(h *adminHandler) {
+		h.invalidators[ctxKey] = func(ctx context.Context, key string) error {
+			k, err := parse(key)
+			if err != nil {
+				return fmt.Errorf("%w: %w", errBadKey, err)
+			}
+			return store.Delete(ctx, k)
+		}
+	}
+}
+
+// AdminHandler returns an http.Handler for live cache inspection.
+//
+// GET responds with ProcessStats as JSON. DELETE ?func_id=<id>&key=<key>
+// invalidates key in the shared store registered with Invalidatable. It
+// responds 400 if key does not parse and 502 if the store fails.
+func AdminHandler(opts ...AdminOption) http.Handler {
+	h := &adminHandler{invalidators: make(map[FuncID]func(context.Context, string) error)}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+func (h *adminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(ProcessStats())
+	case http.MethodDelete:
+		invalidate, ok := h.invalidators[FuncID(r.URL.Query().Get("func_id"))]
+		if !ok {
+			http.Error(w, "unknown func_id", http.StatusNotFound)
+			return
+		}
+		if err := invalidate(r.Context(), r.URL.Query().Get("key")); err != nil {
+			status := http.StatusBadGateway
+			if errors.Is(err, errBadKey) {
+				status = http.StatusBadRequest
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,57 @@
+package ctxcache
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestAdminHandler(t *testing.T) {
+	before := ProcessStats()[FuncID("admin_double")]
+	shared := NewShared[int, int](0)
+	double := func(n int) int { return n * 2 }
+	ctx := WithCache(context.Background(), FuncID("admin_double"), double, WithStore[int, int](shared))
+	f, _ := FromContext(ctx, FuncID("admin_double"), double)
+	f(1)
+	f(1)
+
+	h := AdminHandler(Invalidatable[int, int](FuncID("admin_double"), shared, strconv.Atoi))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	var stats map[FuncID]Stats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatal(err)
+	}
+	if s := stats["admin_double"]; s.Hits-before.Hits != 1 || s.Loads-before.Loads != 1 {
+		t.Fatalf("stats = %+v", s)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/?func_id=admin_double&key=1", nil))
+	if rec.Code != http.StatusNoContent || shared.Len() != 0 {
+		t.Fatalf("DELETE = %d, len = %d", rec.Code, shared.Len())
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/?func_id=other&key=1", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("DELETE unknown = %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/?func_id=admin_double&key=x", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("DELETE bad key = %d", rec.Code)
+	}
+
+	h = AdminHandler(Invalidatable[int, int](FuncID("admin_down"), downStore{}, strconv.Atoi))
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/?func_id=admin_down&key=1", nil))
+	if rec.Code != http.StatusBadGateway {
+		t.Fatalf("DELETE store down = %d", rec.Code)
+	}
+}
@@ -0,0 +1,39 @@
+package ctxcache
+
+// arenaChunk is the number of entries allocated at once by arenaMap.
+const arenaChunk = 1024
+
+// arenaMap is a lockedMap allocating its entries from chunks.
+type arenaMap[K comparable, V any] struct {
+	lockedMap[K, V]
+	chunk []entry[K, V]
+}
+
+// entryAllocator is implemented by entry maps allocating their entries.
+type entryAllocator[K comparable, V any] interface {
+	alloc() *entry[K, V]
+}
+
+// alloc returns a zero entry from the current chunk, starting a new one when
+// it is full. Chunks are freed once none of their entries is referenced.
+func (m *arenaMap[K, V]) alloc() *entry[K, V] {
+	if len(m.chunk) == cap(m.chunk) {
+		m.chunk = make([]entry[K, V], 0, arenaChunk)
+	}
+	m.chunk = m.chunk[:len(m.chunk)+1]
+	return &m.chunk[len(m.chunk)-1]
+}
+
+func (m *arenaMap[K, V]) clear() {
+	m.lockedMap.clear()
+	m.chunk = nil
+}
+
+// newEntry allocates an entry, from the arena of the map if any. c.lock
+// must be held for writing.
+func (c *cache[K, V]) newEntry() *entry[K, V] {
+	if a, ok := c.data.(entryAllocator[K, V]); ok {
+		return a.alloc()
+	}
+	return &entry[K, V]{}
+}
@@ -0,0 +1,40 @@
+package ctxcache
+
+import (
+	"context"
+	"testing"
+)
+
+func TestArena(t *testing.T) {
+	load := func(n int) int { return n * 2 }
+	ctx := WithCache(context.Background(), FuncID("arena"), load, Backend(Arena))
+	f, _ := FromContext(ctx, FuncID("arena"), load)
+	for i := range arenaChunk + 1 {
+		f(i)
+	}
+	m := cacheValue(ctx, FuncID("arena")).(*cache[int, int]).data.(*arenaMap[int, int])
+	if len(m.chunk) != 1 {
+		t.Fatalf("current chunk holds %d entries, want 1", len(m.chunk))
+	}
+	for i := range arenaChunk + 1 {
+		if e, _ := m.load(i); e == nil || e.value != i*2 {
+			t.Fatalf("entry %d = %v", i, e)
+		}
+	}
+	Purge(ctx, FuncID("arena"))
+	if m.chunk != nil || m.len() != 0 {
+		t.Fatal("arena not freed by Purge")
+	}
+}
+
+func TestArenaClearsRemoved(t *testing.T) {
+	load := func(n int) *int { return &n }
+	ctx := WithCache(context.Background(), FuncID("arena"), load, Backend(Arena), MaxEntries(1))
+	f, _ := FromContext(ctx, FuncID("arena"), load)
+	f(1)
+	f(2) // evicts 1
+	m := cacheValue(ctx, FuncID("arena")).(*cache[int, *int]).data.(*arenaMap[int, *int])
+	if e := m.chunk[0]; e.value != nil || e.x != nil {
+		t.Fatalf("evicted entry still holds %v, %v", e.value, e.x)
+	}
+}
@@ -0,0 +1,196 @@
+package ctxcache
+
+import (
+	"iter"
+	"sync"
+	"sync/atomic"
+)
+
+// MapKind selects the map holding the entries of a cache.
+type MapKind int
+
+const (
+	// LockedMap keeps entries in a map guarded by the cache lock. It is the
+	// default.
+	LockedMap MapKind = iota
+	// CopyOnWrite keeps entries in an immutable map swapped atomically on
+	// every write, so hits take no lock. Each write copies the whole map,
+	// which suits caches written rarely and read very often.
+	CopyOnWrite
+	// SyncMap keeps entries in a sync.Map, so hits take no lock and writes
+	// stay cheap. It suits caches with many more reads than writes.
+	SyncMap
+	// OpenAddressing keeps entries in an open-addressing hash table
+	// specialized for integer keys, faster than the built-in map for
+	// caches holding many dense integer keys. Caches with other key types
+	// use LockedMap.
+	OpenAddressing
+	// Arena allocates entries from chunks freed all at once when the cache
+	// is purged or released, cutting allocations and GC work for caches
+	// holding very many small values. The memory of entries invalidated,
+	// evicted or expired one by one is only reclaimed then.
+	Arena
+	// Ordered keeps entries in a B-tree sorted by key, for caches with keys
+	// of an ordered type: Entries yields them in key order and GetRange
+	// finds the keys in a range quickly. Caches with other key types use
+	// LockedMap.
+	Ordered
+)
+
+// Backend selects the map holding the entries of the cache. Hits take no
+// lock with the concurrent kinds unless MaxEntries or MaxCost is set, as
+// recency is then tracked under the lock. Unless MaxIdle, RefreshAhead,
+// TrackAccess or TrackHotKeys is set too, they then write no memory shared
+// with other hits, so concurrent hits of one cache do not contend.
+func Backend(kind MapKind) Option {
+	return func(o *options) {
+		o.backend = kind
+	}
+}
+
+// entryMap maps keys to entries. Writes are made with c.lock held for
+// writing; reads need c.lock held for reading unless concurrent is true.
+type entryMap[K comparable, V any] interface {
+	load(k K) (*entry[K, V], bool)
+	store(k K, e *entry[K, V])
+	delete(k K)
+	clear()
+	len() int
+	all() iter.Seq2[K, *entry[K, V]]
+	concurrent() bool
+}
+
+func newEntryMap[K comparable, V any](kind MapKind) entryMap[K, V] {
+	switch kind {
+	case CopyOnWrite:
+		m := &cowMap[K, V]{}
+		m.m.Store(&map[K]*entry[K, V]{})
+		return m
+	case SyncMap:
+		return &syncMap[K, V]{}
+	case Ordered:
+		if cmp := orderedCompare[K](); cmp != nil {
+			return &orderedMap[K, V]{cmp: cmp}
+		}
+		return lockedMap[K, V]{}
+	case Arena:
+		return &arenaMap[K, V]{lockedMap: lockedMap[K, V]{}}
+	case OpenAddressing:
+		if hash := intHash[K](); hash != nil {
+			return &openMap[K, V]{hash: hash}
+		}
+		return lockedMap[K, V]{}
+	default:
+		return lockedMap[K, V]{}
+	}
+}
+
+type lockedMap[K comparable, V any] map[K]*entry[K, V]
+
+func (m lockedMap[K, V]) load(k K) (*entry[K, V], bool) {
+	e, ok := m[k]
+	return e, ok
+}
+
+func (m lockedMap[K, V]) store(k K, e *entry[K, V]) { m[k] = e }
+
+func (m lockedMap[K, V]) delete(k K) { delete(m, k) }
+
+func (m lockedMap[K, V]) clear() { clear(m) }
+
+func (m lockedMap[K, V]) len() int { return len(m) }
+
+func (m lockedMap[K, V]) all() iter.Seq2[K, *entry[K, V]] {
+	return func(yield func(K, *entry[K, V]) bool) {
+		for k, e := range m {
+			if !yield(k, e) {
+				return
+			}
+		}
+	}
+}
+
+func (lockedMap[K, V]) concurrent() bool { return false }
+
+type cowMap[K comparable, V any] struct {
+	m atomic.Pointer[map[K]*entry[K, V]]
+}
+
+func (m *cowMap[K, V]) load(k K) (*entry[K, V], bool) {
+	e, ok := (*m.m.Load())[k]
+	return e, ok
+}
+
+// update stores a modified copy of the map.
+func (m *cowMap[K, V]) update(fn func(map[K]*entry[K, V])) {
+	old := *m.m.Load()
+	next := make(map[K]*entry[K, V], len(old)+1)
+	for k, e := range old {
+		next[k] = e
+	}
+	fn(next)
+	m.m.Store(&next)
+}
+
+func (m *cowMap[K, V]) store(k K, e *entry[K, V]) {
+	m.update(func(next map[K]*entry[K, V]) { next[k] = e })
+}
+
+func (m *cowMap[K, V]) delete(k K) {
+	if _, ok := m.load(k); ok {
+		m.update(func(next map[K]*entry[K, V]) { delete(next, k) })
+	}
+}
+
+func (m *cowMap[K, V]) clear() { m.m.Store(&map[K]*entry[K, V]{}) }
+
+func (m *cowMap[K, V]) len() int { return len(*m.m.Load()) }
+
+func (m *cowMap[K, V]) all() iter.Seq2[K, *entry[K, V]] {
+	return lockedMap[K, V](*m.m.Load()).all()
+}
+
+func (*cowMap[K, V]) concurrent() bool { return true }
+
+type syncMap[K comparable, V any] struct {
+	m sync.Map
+	// n counts the entries; it only changes with c.lock held for writing.
+	n int
+}
+
+func (m *syncMap[K, V]) load(k K) (*entry[K, V], bool) {
+	e, ok := m.m.Load(k)
+	if !ok {
+		return nil, false
+	}
+	return e.(*entry[K, V]), true
+}
+
+func (m *syncMap[K, V]) store(k K, e *entry[K, V]) {
+	if _, loaded := m.m.Swap(k, e); !loaded {
+		m.n++
+	}
+}
+
+func (m *syncMap[K, V]) delete(k K) {
+	if _, loaded := m.m.LoadAndDelete(k); loaded {
+		m.n--
+	}
+}
+
+func (m *syncMap[K, V]) clear() {
+	m.m.Clear()
+	m.n = 0
+}
+
+func (m *syncMap[K, V]) len() int { return m.n }
+
+func (m *syncMap[K, V]) all() iter.Seq2[K, *entry[K, V]] {
+	return func(yield func(K, *entry[K, V]) bool) {
+		m.m.Range(func(k, e any) bool {
+			return yield(k.(K), e.(*entry[K, V]))
+		})
+	}
+}
+
+func (*syncMap[K, V]) concurrent() bool { return true }
@@ -0,0 +1,63 @@
+package ctxcache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestBackend(t *testing.T) {
+	for _, kind := range []MapKind{LockedMap, CopyOnWrite, SyncMap, OpenAddressing, Arena, Ordered} {
+		var loads atomic.Int64
+		load := func(n int) int {
+			loads.Add(1)
+			return n * 2
+		}
+		ctx := WithCache(context.Background(), FuncID("backend"), load, Backend(kind))
+		f, _ := FromContext(ctx, FuncID("backend"), load)
+
+		var wg sync.WaitGroup
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 100; i++ {
+					if v := f(i % 10); v != i%10*2 {
+						t.Errorf("f(%d) = %d", i%10, v)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		if n := loads.Load(); n < 10 || n > 80 {
+			t.Fatalf("kind %d: %d loads", kind, n)
+		}
+		if !Invalidate(ctx, FuncID("backend"), 3) || Invalidate(ctx, FuncID("backend"), 3) {
+			t.Fatalf("kind %d: invalidate failed", kind)
+		}
+		if s, _ := GetStats(ctx, FuncID("backend")); s.Entries != 9 {
+			t.Fatalf("kind %d: %d entries", kind, s.Entries)
+		}
+	}
+}
+
+func TestBackendPurgeDuringHits(t *testing.T) {
+	for _, kind := range []MapKind{CopyOnWrite, SyncMap} {
+		load := func(n int) int { return n }
+		ctx := WithCache(context.Background(), FuncID("backend_purge"), load, Backend(kind))
+		f, _ := FromContext(ctx, FuncID("backend_purge"), load)
+		f(1)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 100; i++ {
+				f(1)
+			}
+		}()
+		for i := 0; i < 10; i++ {
+			Purge(ctx, FuncID("backend_purge"))
+		}
+		<-done
+	}
+}
@@ -0,0 +1,79 @@
+package ctxcache
+
+import (
+	"sync"
+	"time"
+)
+
+// BatchFunc loads many keys at once. Keys missing from the result map load
+// as the zero value.
+type BatchFunc[K comparable, V any] func(keys []K) map[K]V
+
+type batcher[K comparable, V any] struct {
+	fn       BatchFunc[K, V]
+	wait     time.Duration
+	maxBatch int
+
+	lock    sync.Mutex
+	pending *batch[K, V]
+}
+
+type batch[K comparable, V any] struct {
+	keys       []K
+	seen       map[K]bool
+	dispatched bool
+	timer      *time.Timer
+	done       chan struct{}
+	results    map[K]V
+}
+
+// Batch returns a CacheFunc coalescing the calls made within wait of each
+// other into a single call of fn, like a GraphQL dataloader. A batch is also
+// dispatched as soon as it holds maxBatch keys, if maxBatch > 0.
+//
+// Install the result with WithCache so each context gets its own batcher:
+//
+//	ctx = ctxcache.WithCache(ctx, "user", ctxcache.Batch(loadUsers, time.Millisecond, 100))
+func Batch[K comparable, V any](fn BatchFunc[K, V], wait time.Duration, maxBatch int) CacheFunc[K, V] {
+	b := &batcher[K, V]{fn: fn, wait: wait, maxBatch: maxBatch}
+	return b.load
+}
+
+func (b *batcher[K, V]) load(k K) V {
+	b.lock.Lock()
+	p := b.pending
+	if p == nil {
+		p = &batch[K, V]{seen: make(map[K]bool), done: make(chan struct{})}
+		b.pending = p
+		p.timer = time.AfterFunc(b.wait, func() { b.dispatch(p) })
+	}
+	if !p.seen[k] {
+		p.seen[k] = true
+		p.keys = append(p.keys, k)
+	}
+	full := b.maxBatch > 0 && len(p.keys) >= b.maxBatch
+	b.lock.Unlock()
+
+	if full {
+		b.dispatch(p)
+	}
+	<-p.done
+	return p.results[k]
+}
+
+func (b *batcher[K, V]) dispatch(p *batch[K, V]) {
+	b.lock.Lock()
+	if b.pending == p {
+		b.pending = nil
+	}
+	if p.dispatched {
+		b.lock.Unlock()
+		return
+	}
+	p.dispatched = true
+	p.timer.Stop()
+	b.lock.Unlock()
+
+	defer close(p.done)
+	p.results = b.fn(p.keys)
+}
@@ -0,0 +1,59 @@
+package ctxcache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBatch(t *testing.T) {
+	var calls [][]int
+	var lock sync.Mutex
+	square := func(keys []int) map[int]int {
+		lock.Lock()
+		calls = append(calls, keys)
+		lock.Unlock()
+		res := make(map[int]int)
+		for _, k := range keys {
+			res[k] = k * k
+		}
+		return res
+	}
+	ctx := WithCache(context.Background(), FuncID("square"), Batch(square, 10*time.Millisecond, 0))
+	f, _ := FromContext[int, int](ctx, FuncID("square"), nil)
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 5; i++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			if got := f(n); got != n*n {
+				t.Errorf("f(%d) = %d", n, got)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if len(calls) != 1 || len(calls[0]) != 5 {
+		t.Fatalf("calls = %v", calls)
+	}
+	if f(3) != 9 || len(calls) != 1 {
+		t.Fatalf("cached key reloaded: %v", calls)
+	}
+}
+
+func TestBatchMax(t *testing.T) {
+	calls := 0
+	ident := func(keys []int) map[int]int {
+		calls++
+		res := make(map[int]int)
+		for _, k := range keys {
+			res[k] = k
+		}
+		return res
+	}
+	f := Batch(ident, time.Hour, 1)
+	if f(1) != 1 || f(2) != 2 || calls != 2 {
+		t.Fatalf("calls = %d", calls)
+	}
+}
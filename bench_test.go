@@ -0,0 +1,83 @@
+package ctxcache
+
+import (
+	"context"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+func benchLoad(n int) int { return n * 2 }
+
+// TestHitAllocs keeps the hit path free of allocations.
+func TestHitAllocs(t *testing.T) {
+	for _, kind := range []MapKind{LockedMap, CopyOnWrite, SyncMap, OpenAddressing, Arena, Ordered} {
+		ctx := WithCache(context.Background(), FuncID("bench"), benchLoad, Backend(kind), MaxIdle(time.Hour))
+		f, _ := FromContext(ctx, FuncID("bench"), benchLoad)
+		f(1)
+		if n := testing.AllocsPerRun(100, func() { f(1) }); n != 0 {
+			t.Errorf("%s: %v allocs per hit", benchKind(kind), n)
+		}
+	}
+}
+
+func TestEntrySize(t *testing.T) {
+	// key, value, created and the optional state pointer, nothing more.
+	want := 2*unsafe.Sizeof(int(0)) + unsafe.Sizeof(int64(0)) + unsafe.Sizeof(uintptr(0))
+	if n := unsafe.Sizeof(entry[int, int]{}); n != want {
+		t.Fatalf("int entry takes %d bytes, want %d", n, want)
+	}
+	ctx := WithCache(context.Background(), FuncID("bench"), benchLoad)
+	f, _ := FromContext(ctx, FuncID("bench"), benchLoad)
+	f(1)
+	if e, _ := cacheValue(ctx, FuncID("bench")).(*cache[int, int]).data.load(1); e.x != nil {
+		t.Fatalf("plain entry has optional state %+v", e.x)
+	}
+}
+
+func BenchmarkHit(b *testing.B) {
+	for _, kind := range []MapKind{LockedMap, CopyOnWrite, SyncMap, OpenAddressing, Arena, Ordered} {
+		ctx := WithCache(context.Background(), FuncID("bench"), benchLoad, Backend(kind))
+		f, _ := FromContext(ctx, FuncID("bench"), benchLoad)
+		f(1)
+		b.Run(benchKind(kind), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				f(1)
+			}
+		})
+	}
+}
+
+func BenchmarkMiss(b *testing.B) {
+	ctx := WithCache(context.Background(), FuncID("bench"), benchLoad)
+	f, _ := FromContext(ctx, FuncID("bench"), benchLoad)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		f(i)
+	}
+}
+
+func BenchmarkConcurrent(b *testing.B) {
+	for _, kind := range []MapKind{LockedMap, CopyOnWrite, SyncMap, OpenAddressing, Arena, Ordered} {
+		ctx := WithCache(context.Background(), FuncID("bench"), benchLoad, Backend(kind))
+		f, _ := FromContext(ctx, FuncID("bench"), benchLoad)
+		for i := 0; i < 64; i++ {
+			f(i)
+		}
+		b.Run(benchKind(kind), func(b *testing.B) {
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					f(i % 64)
+					i++
+				}
+			})
+		})
+	}
+}
+
+func benchKind(kind MapKind) string {
+	return [...]string{"locked", "cow", "syncmap", "open", "arena", "ordered"}[kind]
+}
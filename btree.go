@@ -0,0 +1,265 @@
+package ctxcache
+
+import (
+	"cmp"
+	"iter"
+	"reflect"
+	"slices"
+	"sort"
+	"unsafe"
+)
+
+// btreeMaxItems is the most items a B-tree node holds before splitting.
+const btreeMaxItems = 31
+
+// orderedMap keeps entries in a B-tree ordered by key, for the Ordered
+// backend. It is guarded by the cache lock like lockedMap.
+type orderedMap[K comparable, V any] struct {
+	root *bnode[K, V]
+	n    int
+	cmp  func(a, b K) int
+}
+
+type bnode[K comparable, V any] struct {
+	items    []*entry[K, V]
+	children []*bnode[K, V]
+}
+
+// orderedCompare returns a function comparing keys of an ordered kind, or
+// nil if K is not ordered.
+func orderedCompare[K comparable]() func(a, b K) int {
+	switch reflect.TypeFor[K]().Kind() {
+	case reflect.Int:
+		return compareAs[K, int]
+	case reflect.Int8:
+		return compareAs[K, int8]
+	case reflect.Int16:
+		return compareAs[K, int16]
+	case reflect.Int32:
+		return compareAs[K, int32]
+	case reflect.Int64:
+		return compareAs[K, int64]
+	case reflect.Uint:
+		return compareAs[K, uint]
+	case reflect.Uint8:
+		return compareAs[K, uint8]
+	case reflect.Uint16:
+		return compareAs[K, uint16]
+	case reflect.Uint32:
+		return compareAs[K, uint32]
+	case reflect.Uint64:
+		return compareAs[K, uint64]
+	case reflect.Uintptr:
+		return compareAs[K, uintptr]
+	case reflect.Float32:
+		return compareAs[K, float32]
+	case reflect.Float64:
+		return compareAs[K, float64]
+	case reflect.String:
+		return compareAs[K, string]
+	}
+	return nil
+}
+
+// compareAs compares a and b as their underlying type T.
+func compareAs[K any, T cmp.Ordered](a, b K) int {
+	return cmp.Compare(*(*T)(unsafe.Pointer(&a)), *(*T)(unsafe.Pointer(&b)))
+}
+
+// find returns the index of the first item not below k, and whether it
+// holds k.
+func (m *orderedMap[K, V]) find(n *bnode[K, V], k K) (int, bool) {
+	i := sort.Search(len(n.items), func(i int) bool { return m.cmp(n.items[i].key, k) >= 0 })
+	return i, i < len(n.items) && m.cmp(n.items[i].key, k) == 0
+}
+
+func (m *orderedMap[K, V]) load(k K) (*entry[K, V], bool) {
+	for n := m.root; n != nil; {
+		i, found := m.find(n, k)
+		if found {
+			return n.items[i], true
+		}
+		if len(n.children) == 0 {
+			break
+		}
+		n = n.children[i]
+	}
+	return nil, false
+}
+
+func (m *orderedMap[K, V]) store(k K, e *entry[K, V]) {
+	if m.root == nil {
+		m.root = &bnode[K, V]{items: []*entry[K, V]{e}}
+		m.n++
+		return
+	}
+	if len(m.root.items) >= btreeMaxItems {
+		mid, next := m.root.split(btreeMaxItems / 2)
+		m.root = &bnode[K, V]{items: []*entry[K, V]{mid}, children: []*bnode[K, V]{m.root, next}}
+	}
+	if !m.insert(m.root, e) {
+		m.n++
+	}
+}
+
+// insert adds e below n, whose items are not full, and reports whether it
+// replaced an entry.
+func (m *orderedMap[K, V]) insert(n *bnode[K, V], e *entry[K, V]) bool {
+	i, found := m.find(n, e.key)
+	if found {
+		n.items[i] = e
+		return true
+	}
+	if len(n.children) == 0 {
+		n.items = slices.Insert(n.items, i, e)
+		return false
+	}
+	if len(n.children[i].items) >= btreeMaxItems {
+		mid, next := n.children[i].split(btreeMaxItems / 2)
+		n.items = slices.Insert(n.items, i, mid)
+		n.children = slices.Insert(n.children, i+1, next)
+		switch c := m.cmp(e.key, mid.key); {
+		case c == 0:
+			n.items[i] = e
+			return true
+		case c > 0:
+			i++
+		}
+	}
+	return m.insert(n.children[i], e)
+}
+
+// split moves the items after i to a new node, returning item i and the
+// new node.
+func (n *bnode[K, V]) split(i int) (*entry[K, V], *bnode[K, V]) {
+	mid := n.items[i]
+	next := &bnode[K, V]{items: slices.Clone(n.items[i+1:])}
+	n.items = slices.Clip(n.items[:i])
+	if len(n.children) > 0 {
+		next.children = slices.Clone(n.children[i+1:])
+		n.children = slices.Clip(n.children[:i+1])
+	}
+	return mid, next
+}
+
+func (m *orderedMap[K, V]) delete(k K) {
+	if m.root == nil || !m.remove(m.root, k) {
+		return
+	}
+	m.n--
+	if len(m.root.items) == 0 {
+		if len(m.root.children) > 0 {
+			m.root = m.root.children[0]
+		} else {
+			m.root = nil
+		}
+	}
+}
+
+// btreeMinItems is the fewest items a node other than the root holds.
+const btreeMinItems = btreeMaxItems / 2
+
+// remove deletes k below n and reports whether it was found. Children are
+// grown before descending so that they can lose an item.
+func (m *orderedMap[K, V]) remove(n *bnode[K, V], k K) bool {
+	i, found := m.find(n, k)
+	if len(n.children) == 0 {
+		if found {
+			n.items = slices.Delete(n.items, i, i+1)
+		}
+		return found
+	}
+	if len(n.children[i].items) <= btreeMinItems {
+		m.grow(n, i)
+		return m.remove(n, k)
+	}
+	if found {
+		// Replace k with its predecessor, the maximum of the left child.
+		pred := n.children[i]
+		for len(pred.children) > 0 {
+			pred = pred.children[len(pred.children)-1]
+		}
+		n.items[i] = pred.items[len(pred.items)-1]
+		return m.remove(n.children[i], n.items[i].key)
+	}
+	return m.remove(n.children[i], k)
+}
+
+// grow gives child i of n an extra item, taken from a sibling or by merging
+// it with one.
+func (m *orderedMap[K, V]) grow(n *bnode[K, V], i int) {
+	child := n.children[i]
+	switch {
+	case i > 0 && len(n.children[i-1].items) > btreeMinItems:
+		left := n.children[i-1]
+		child.items = slices.Insert(child.items, 0, n.items[i-1])
+		n.items[i-1] = left.items[len(left.items)-1]
+		left.items = left.items[:len(left.items)-1]
+		if len(left.children) > 0 {
+			child.children = slices.Insert(child.children, 0, left.children[len(left.children)-1])
+			left.children = left.children[:len(left.children)-1]
+		}
+	case i < len(n.items) && len(n.children[i+1].items) > btreeMinItems:
+		right := n.children[i+1]
+		child.items = append(child.items, n.items[i])
+		n.items[i] = right.items[0]
+		right.items = slices.Delete(right.items, 0, 1)
+		if len(right.children) > 0 {
+			child.children = append(child.children, right.children[0])
+			right.children = slices.Delete(right.children, 0, 1)
+		}
+	default:
+		if i == len(n.items) {
+			i--
+			child = n.children[i]
+		}
+		next := n.children[i+1]
+		child.items = append(append(child.items, n.items[i]), next.items...)
+		child.children = append(child.children, next.children...)
+		n.items = slices.Delete(n.items, i, i+1)
+		n.children = slices.Delete(n.children, i+1, i+2)
+	}
+}
+
+func (m *orderedMap[K, V]) clear() {
+	m.root, m.n = nil, 0
+}
+
+func (m *orderedMap[K, V]) len() int { return m.n }
+
+// all yields the entries in key order.
+func (m *orderedMap[K, V]) all() iter.Seq2[K, *entry[K, V]] {
+	return func(yield func(K, *entry[K, V]) bool) {
+		m.ascend(m.root, nil, nil, yield)
+	}
+}
+
+// ascend yields the entries below n with keys between lo and hi included,
+// in order, a nil bound being open. It returns false once iteration stops.
+func (m *orderedMap[K, V]) ascend(n *bnode[K, V], lo, hi *K, yield func(K, *entry[K, V]) bool) bool {
+	if n == nil {
+		return true
+	}
+	i := 0
+	if lo != nil {
+		i, _ = m.find(n, *lo)
+	}
+	for ; i < len(n.items); i++ {
+		if len(n.children) > 0 && !m.ascend(n.children[i], lo, hi, yield) {
+			return false
+		}
+		e := n.items[i]
+		if hi != nil && m.cmp(e.key, *hi) > 0 {
+			return false
+		}
+		if !yield(e.key, e) {
+			return false
+		}
+	}
+	if len(n.children) > 0 {
+		return m.ascend(n.children[len(n.items)], lo, hi, yield)
+	}
+	return true
+}
+
+func (*orderedMap[K, V]) concurrent() bool { return false }
@@ -0,0 +1,96 @@
+package ctxcache
+
+import (
+	"context"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestOrderedMap(t *testing.T) {
+	m := newEntryMap[int, int](Ordered).(*orderedMap[int, int])
+	want := map[int]*entry[int, int]{}
+	r := rand.New(rand.NewPCG(1, 2))
+	for round := range 20000 {
+		k := r.IntN(3000)
+		if round < 10000 || r.IntN(2) == 0 {
+			e := &entry[int, int]{key: k}
+			m.store(k, e)
+			want[k] = e
+		} else {
+			m.delete(k)
+			delete(want, k)
+		}
+	}
+	if m.len() != len(want) {
+		t.Fatalf("len = %d, want %d", m.len(), len(want))
+	}
+	for k := range 3000 {
+		if e, ok := m.load(k); e != want[k] || ok != (want[k] != nil) {
+			t.Fatalf("load(%d) = %v, %v", k, e, ok)
+		}
+	}
+	var keys []int
+	for k, e := range m.all() {
+		if want[k] != e {
+			t.Fatalf("all yields %d", k)
+		}
+		keys = append(keys, k)
+	}
+	if !slices.Equal(keys, slices.Sorted(maps.Keys(want))) {
+		t.Fatal("all not in key order")
+	}
+	for k := range want {
+		m.delete(k)
+	}
+	if m.len() != 0 || m.root != nil {
+		t.Fatal("entries left after deleting every key")
+	}
+
+	if _, ok := newEntryMap[struct{ a int }, int](Ordered).(lockedMap[struct{ a int }, int]); !ok {
+		t.Fatal("unordered keys do not fall back to LockedMap")
+	}
+}
+
+func TestGetRange(t *testing.T) {
+	for _, kind := range []MapKind{Ordered, LockedMap} {
+		load := func(n int) int { return n * 10 }
+		ctx := WithCache(context.Background(), FuncID("range"), load, Backend(kind))
+		f, _ := FromContext(ctx, FuncID("range"), load)
+		for _, n := range rand.Perm(200) {
+			f(n)
+		}
+		Invalidate(ctx, FuncID("range"), 52)
+		var keys []int
+		for k, v := range GetRange[int, int](ctx, FuncID("range"), 50, 55) {
+			if v != k*10 {
+				t.Fatalf("value of %d = %d", k, v)
+			}
+			keys = append(keys, k)
+		}
+		if !slices.Equal(keys, []int{50, 51, 53, 54, 55}) {
+			t.Fatalf("kind %d: GetRange = %v", kind, keys)
+		}
+		for k := range GetRange[int, int](ctx, FuncID("range"), 0, 199) {
+			if k != 0 {
+				t.Fatalf("kind %d: first key %d", kind, k)
+			}
+			break
+		}
+	}
+}
+
+func TestGetRangeReentrant(t *testing.T) {
+	load := func(n int) int { return n * 10 }
+	ctx := WithCache(context.Background(), FuncID("range"), load, Backend(Ordered), MaxEntries(100))
+	f, _ := FromContext(ctx, FuncID("range"), load)
+	for n := range 10 {
+		f(n)
+	}
+	for k, v := range GetRange[int, int](ctx, FuncID("range"), 0, 9) {
+		if f(k) != v || f(k+10) != v+100 {
+			t.Fatalf("lookups of %d in the loop body failed", k)
+		}
+	}
+}
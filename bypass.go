@@ -0,0 +1,19 @@
+package ctxcache
+
+import "context"
+
+type bypassReadsKey struct{}
+
+// BypassReads returns a context in which every cache call misses and calls
+// the loader, still storing its result. It allows debugging stale data
+// reports on a single request while refreshing the caches for others.
+// Frozen caches are still read.
+func BypassReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassReadsKey{}, true)
+}
+
+// readsBypassed reports whether ctx was returned by BypassReads.
+func readsBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(bypassReadsKey{}).(bool)
+	return bypass
+}
@@ -0,0 +1,24 @@
+package ctxcache
+
+import (
+	"context"
+	"testing"
+)
+
+func TestBypassReads(t *testing.T) {
+	loads := 0
+	next := func(int) int {
+		loads++
+		return loads
+	}
+	ctx := WithCache(context.Background(), FuncID("next"), next)
+	f, _ := FromContext(ctx, FuncID("next"), next)
+	f(1)
+	g, _ := FromContext(BypassReads(ctx), FuncID("next"), next)
+	if v := g(1); v != 2 {
+		t.Fatalf("g(1) = %d, want a reload", v)
+	}
+	if v := f(1); v != 2 || loads != 2 {
+		t.Fatalf("f(1) = %d after %d loads, want the reloaded value", v, loads)
+	}
+}
@@ -1,50 +1,564 @@
 package ctxcache
 
 import (
+	"container/heap"
+	"container/list"
 	"context"
+	"fmt"
+	"reflect"
 	"sync"
+	"sync/atomic"
+	"time"
+	"unique"
 )
 
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+	// created is the UnixNano time of the load.
+	created int64
+	// x holds the state only some options need, nil if none of them is set.
+	x *entryExtra[K, V]
+}
+
+// entryExtra is the part of an entry kept only for the options using it, so
+// that plain entries stay small.
+type entryExtra[K comparable, V any] struct {
+	// err is the error of a failed load cached by CacheErrors.
+	err error
+	// version is the WithVersion token the entry was written under.
+	version string
+	// cost and elem place the entry in a bounded cache.
+	cost int64
+	elem *list.Element
+	// accessed is the UnixNano time of the last read and hits the number of
+	// reads, tracked only when tracksAccess.
+	accessed atomic.Int64
+	hits     atomic.Uint64
+	// expiry is the UnixNano time the entry expires at, zero if never.
+	expiry int64
+	// due orders the entry in the expiry heap; the entry may expire at due
+	// but not before. index is its position there, -1 if absent.
+	due   int64
+	index int
+	// weak holds the value instead of value with WeakValues.
+	weak   weakRef
+	weakly bool
+	// snapshot is a deep copy of value taken on insert, for GuardMutations.
+	snapshot V
+}
+
+// extra returns the optional state of e, allocating it on first use. The
+// entry must not be visible to readers yet, or c.lock be held for writing.
+func (e *entry[K, V]) extra() *entryExtra[K, V] {
+	if e.x == nil {
+		e.x = &entryExtra[K, V]{index: -1}
+	}
+	return e.x
+}
+
+// err returns the cached error of e, if any.
+func (e *entry[K, V]) err() error {
+	if e.x == nil {
+		return nil
+	}
+	return e.x.err
+}
+
+// version returns the WithVersion token e was written under.
+func (e *entry[K, V]) version() string {
+	if e.x == nil {
+		return ""
+	}
+	return e.x.version
+}
+
+// expiry returns the UnixNano time e expires at, zero if never.
+func (e *entry[K, V]) expiry() int64 {
+	if e.x == nil {
+		return 0
+	}
+	return e.x.expiry
+}
+
 type cache[K comparable, V any] struct {
+	id     FuncID
 	lock   sync.RWMutex
-	data   map[K]V
-	loader func(K) V
+	data   entryMap[K, V]
+	loader CacheFuncE[K, V]
+	opts   *options
+	store  Store[K, V]
+
+	// lru orders entries by recency when the cache is bounded.
+	lru    *list.List
+	cost   int64
+	costFn func(K, V) int64
+
+	// expiries orders the entries with a TTL or MaxIdle by due time.
+	expiries expiryHeap[K, V]
+
+	fallbackFn func(K) V
+	pins       []func(K) bool
+	cloneFn    func(V) V
+	redactFn   func(K) string
+	shadowFn   func(context.Context, K, V, V)
+
+	counters counters
+	slowest  slowLoads
+	hot      *topK[K]
+	process  *counters
+	live     *liveEntries
+	// warned is set once WarnAboveEntries has fired.
+	warned atomic.Bool
+	// reserved counts the loads started, for MaxLoads.
+	reserved atomic.Int64
+
+	// inflight holds the loads in progress for StrictOnce.
+	inflight map[flight[K]]*call[V]
+	// dependents holds the entries derived from each key, by DependsOn, and
+	// parents the entries each key is derived from. deps guards them; it is
+	// never held while taking another lock, so caches can update each
+	// other's links with their own lock held.
+	deps       sync.Mutex
+	dependents map[K]map[loading]struct{}
+	parents    map[K]map[loading]struct{}
+	// handles holds the unique handles of the keys with InternKeys.
+	handles map[K]unique.Handle[K]
+
+	// subscribers receive the events of the cache; emitting is held for
+	// reading while sending to them.
+	subscribers atomic.Pointer[[]chan Event]
+	emitting    sync.RWMutex
+
+	// frozen is set by Freeze.
+	frozen atomic.Pointer[frozen[K, V]]
+	// storeErr is the error of the last call to the store.
+	storeErr atomic.Pointer[error]
+
+	// root is the context the cache is installed in. spaces holds the
+	// partitions of the cache by Namespace, created in root. Partitions
+	// have their namespace in ns and the cache in parent. Partitions that
+	// cannot be served fail every call with refused.
+	root       context.Context
+	spaces     sync.Map
+	spacesLock sync.Mutex
+	ns         string
+	parent     *cache[K, V]
+	refused    error
+
+	// closed is set once the owning context is done.
+	closed bool
+
+	// stopped is closed to stop the background workers of the cache.
+	stopped  chan struct{}
+	stopOnce sync.Once
 }
 
-func (c *cache[K, V]) cacheLoader(k K) V {
-	c.lock.RLock()
-	v, ok := c.data[k]
+func (c *cache[K, V]) cacheLoader(ctx context.Context, sc scope, k K) (V, error) {
+	return c.fetch(ctx, sc, k, c.opts.strictOnce)
+}
+
+// scope is what a context says about reading the caches. It is resolved
+// once when a function is bound to the context, as walking the context on
+// every hit would cost more than the hit itself.
+type scope struct {
+	version       string
+	readsBypassed bool
+}
+
+func scopeOf(ctx context.Context) scope {
+	return scope{version: versionOf(ctx), readsBypassed: readsBypassed(ctx)}
+}
+
+// fetch returns the value of k, sharing concurrent loads of k if once is
+// true.
+func (c *cache[K, V]) fetch(ctx context.Context, sc scope, k K, once bool) (V, error) {
+	if c.refused != nil {
+		var zero V
+		return c.fallback(k, zero, c.refused)
+	}
+	if c.bypass(ctx) {
+		return c.loadBypassed(ctx, k)
+	}
+	v, err := c.get(ctx, sc, k, once)
+	if err == nil && c.cloneFn != nil {
+		v = c.cloneFn(v)
+	}
+	return v, err
+}
+
+func (c *cache[K, V]) get(ctx context.Context, sc scope, k K, once bool) (V, error) {
+	if fz := c.frozen.Load(); fz != nil {
+		return c.frozenGet(ctx, k, fz)
+	}
+	if sc.readsBypassed {
+		return c.load(ctx, k)
+	}
+	v, err, ok := c.lookup(k, sc.version)
 	if ok {
-		c.lock.RUnlock()
-		return v
+		c.countHit()
+		c.emit(ctx, EventHit, k, 0, nil)
+		if c.hot != nil {
+			c.hot.add(k)
+		}
+		if err != nil {
+			return c.fallback(k, v, err)
+		}
+		c.shadow(ctx, k, v)
+		return v, nil
+	}
+	c.countMiss()
+	c.logMiss(ctx, k)
+	c.emit(ctx, EventMiss, k, 0, nil)
+	// Stores keep no version, so they are not read under one.
+	if sc.version == "" {
+		if v, ok = c.storeGet(ctx, k); ok {
+			c.set(ctx, k, v, nil, 0)
+			return v, nil
+		}
+	}
+	if once {
+		return c.loadOnce(ctx, k)
+	}
+	// Concurrent misses of k may each call the loader; StrictOnce prevents
+	// it.
+	return c.load(ctx, k)
+}
+
+// load calls the loader for k and caches its result.
+func (c *cache[K, V]) load(ctx context.Context, k K) (V, error) {
+	if c.opts.skipNear > 0 && nearDeadline(ctx, c.opts.skipNear) {
+		return c.skip(ctx, k)
+	}
+	if err := c.reserveLoad(ctx); err != nil {
+		var zero V
+		return c.fallback(k, zero, err)
+	}
+	token := c.storeToken()
+	start := time.Now()
+	v, d, err := c.call(ctx, k)
+	if err != nil {
+		if c.cacheable(err) {
+			c.set(ctx, k, v, err, d)
+		}
+		return c.fallback(k, v, err)
+	}
+	if c.storedSince(k, start) {
+		c.countDuplicate()
+	}
+	c.set(ctx, k, v, nil, d)
+	c.storeSet(ctx, k, v, token, d)
+
+	return v, nil
+}
+
+// call calls the loader for k with invoke and records the load.
+func (c *cache[K, V]) call(ctx context.Context, k K) (V, time.Duration, error) {
+	v, d, err := c.invoke(ctx, k)
+	c.countLoad(d)
+	c.slowest.record(d, func() string { return c.keyString(k) })
+	c.logLoad(ctx, k, d, err)
+	c.emit(ctx, EventLoad, k, d, err)
+	if err != nil {
+		c.countError()
+	}
+	return v, d, err
+}
+
+// invoke calls the loader for k, wrapped as the options require.
+func (c *cache[K, V]) invoke(ctx context.Context, k K) (V, time.Duration, error) {
+	start := time.Now()
+	loader := c.currentLoader()
+	if c.opts.chaosLatency > 0 || c.opts.chaosRate > 0 {
+		loader = chaos(loader, c.opts.chaosLatency, c.opts.chaosRate)
+	}
+	if c.opts.pprofLabels {
+		loader = c.labeled(loader)
+	}
+	if c.opts.hedgeDelay > 0 {
+		loader = hedged(loader, c.opts.hedgeDelay)
+	}
+	// Recording and workflow replay wrap the rest, so that they see a single
+	// outcome per load, however many calls hedging made.
+	if c.opts.recording != nil {
+		loader = c.recorded(loader)
+	}
+	loader = c.deterministic(ctx, loader)
+	v, err := loader(c.loading(ctx, k), k)
+	return v, time.Since(start), err
+}
+
+// storedSince reports whether an entry for k was stored at or after start.
+func (c *cache[K, V]) storedSince(k K, start time.Time) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	e, ok := c.data.load(k)
+	return ok && e.created >= start.UnixNano()
+}
+
+func (c *cache[K, V]) lookup(k K, version string) (V, error, bool) {
+	switch {
+	case c.lru != nil:
+		c.lock.Lock()
+		defer c.lock.Unlock()
+	case !c.data.concurrent():
+		c.lock.RLock()
+		defer c.lock.RUnlock()
+	}
+	e, ok := c.data.load(k)
+	if !ok || e.version() != version {
+		var zero V
+		return zero, nil, false
+	}
+	x := e.x
+	var now int64
+	if x != nil && (x.expiry != 0 || c.opts.tracksAccess()) {
+		now = time.Now().UnixNano()
+		if c.expired(e, now) {
+			var zero V
+			return zero, nil, false
+		}
+	}
+	v, alive := e.get()
+	if !alive {
+		return v, nil, false
+	}
+	if x == nil {
+		return v, nil, true
 	}
-	c.lock.RUnlock()
-	// TODO: lock by k
+	if x.elem != nil {
+		c.lru.MoveToFront(x.elem)
+	}
+	c.verify(e)
+	if c.opts.tracksAccess() {
+		x.accessed.Store(now)
+		x.hits.Add(1)
+	}
+	return v, x.err, true
+}
+
+func (c *cache[K, V]) set(ctx context.Context, k K, v V, err error, loadTime time.Duration) {
 	c.lock.Lock()
-	defer c.lock.Unlock()
-	v = c.loader(k)
-	c.data[k] = v
+	evicted := c.insert(k, v, err, versionOf(ctx), loadTime)
+	n := c.data.len()
+	c.lock.Unlock()
+	c.evicted(ctx, evicted)
+	c.checkGrowth(ctx, n)
+}
 
-	return v
+// insert stores v and err under k, loaded in loadTime under version, and
+// evicts entries over the bounds, returning their keys. c.lock must be held
+// for writing.
+func (c *cache[K, V]) insert(k K, v V, err error, version string, loadTime time.Duration) []K {
+	if c.closed {
+		return nil
+	}
+	// The old entry is removed first, as that releases the handle of its
+	// key. The links of the key are kept for the new entry.
+	if old, ok := c.data.load(k); ok {
+		c.discard(old)
+	}
+	if c.opts.internKeys {
+		k = c.intern(k)
+	}
+	e := c.newEntry()
+	*e = entry[K, V]{key: k, value: v}
+	if err != nil || version != "" {
+		x := e.extra()
+		x.err, x.version = err, version
+	}
+	if c.opts.guard {
+		e.extra().snapshot = DeepClone(v)
+	}
+	if c.opts.weakValues {
+		e.holdWeakly()
+	}
+	now := time.Now()
+	e.created = now.UnixNano()
+	if c.opts.tracksAccess() {
+		e.extra().accessed.Store(e.created)
+	}
+	ttl := c.ttl(loadTime)
+	if err != nil && c.opts.cooldown > 0 {
+		ttl = c.opts.cooldown
+	}
+	if ttl > 0 {
+		e.extra().expiry = now.Add(ttl).UnixNano()
+	}
+	if c.lru != nil && !c.pinned(k) {
+		x := e.extra()
+		if c.costFn != nil {
+			x.cost = c.costFn(k, v)
+			if x.cost > c.opts.maxCost {
+				c.unintern(k)
+				return nil
+			}
+		}
+		x.elem = c.lru.PushFront(e)
+		c.cost += x.cost
+	}
+	c.data.store(k, e)
+	c.live.add(1)
+	if due := c.deadline(e); due != 0 {
+		e.x.due = due
+		heap.Push(&c.expiries, e)
+	}
+	return c.evict()
+}
+
+// remove deletes e from the cache, along with its DependsOn links. c.lock
+// must be held for writing.
+func (c *cache[K, V]) remove(e *entry[K, V]) {
+	k := e.key
+	c.discard(e)
+	c.unlink(k)
+}
+
+// discard deletes e from the cache and clears it if it was allocated from
+// an arena, where it stays until its whole chunk is freed. c.lock must be
+// held for writing.
+func (c *cache[K, V]) discard(e *entry[K, V]) {
+	c.data.delete(e.key)
+	c.live.add(-1)
+	c.unintern(e.key)
+	if x := e.x; x != nil {
+		if x.elem != nil {
+			c.lru.Remove(x.elem)
+			c.cost -= x.cost
+		}
+		if x.index >= 0 {
+			heap.Remove(&c.expiries, x.index)
+		}
+	}
+	if _, ok := c.data.(entryAllocator[K, V]); ok {
+		*e = entry[K, V]{}
+	}
 }
 
 type FuncID string
 
+// funcKey is the context key of the cache installed under a FuncID. Being
+// unexported, it cannot collide with keys set by other packages, even ones
+// convertible to FuncID.
+type funcKey struct{ id FuncID }
+
+// Installed reports whether a cache is installed in ctx under ctxKey. Code
+// that checked ctx.Value(ctxKey) directly must use it instead, as caches are
+// no longer stored under the FuncID itself.
+func Installed(ctx context.Context, ctxKey FuncID) bool {
+	return cacheValue(ctx, ctxKey) != nil
+}
+
 type CacheFunc[K comparable, V any] func(K) V
 
-func WithCache[K comparable, V any](ctx context.Context, ctxKey FuncID, f CacheFunc[K, V]) context.Context {
+// CacheFuncE is a loader that can fail. It receives the context the cache
+// is called with. Errors are returned to the caller and not cached.
+type CacheFuncE[K comparable, V any] func(ctx context.Context, key K) (V, error)
+
+func WithCache[K comparable, V any](ctx context.Context, ctxKey FuncID, f CacheFunc[K, V], opts ...Option) context.Context {
+	return WithCacheE(ctx, ctxKey, plainLoader(f), opts...)
+}
+
+func plainLoader[K comparable, V any](f CacheFunc[K, V]) CacheFuncE[K, V] {
+	return func(_ context.Context, k K) (V, error) {
+		return f(k), nil
+	}
+}
+
+// WithCacheE is WithCache for loaders that can fail.
+func WithCacheE[K comparable, V any](ctx context.Context, ctxKey FuncID, f CacheFuncE[K, V], opts ...Option) context.Context {
+	cache := newCache(ctx, ctxKey, f, newOptions(opts))
+	cache.start(ctx)
+	ctx = context.WithValue(ctx, funcKey{ctxKey}, cache)
+	return register(ctx, cache)
+}
+
+// newCache returns a cache installed in ctx.
+func newCache[K comparable, V any](ctx context.Context, ctxKey FuncID, f CacheFuncE[K, V], opts *options) *cache[K, V] {
 	cache := &cache[K, V]{
-		loader: f,
-		data:   make(map[K]V),
+		id:      ctxKey,
+		loader:  f,
+		opts:    opts,
+		root:    ctx,
+		process: processCounters(ctxKey),
+		stopped: make(chan struct{}),
+	}
+	cache.data = newEntryMap[K, V](cache.opts.backend)
+	cache.live = newLiveEntries(cache.process)
+	if cache.opts.hotKeys > 0 {
+		cache.hot = &topK[K]{n: cache.opts.hotKeys, counts: make(map[K]*KeyCount)}
+	}
+	if cache.opts.store != nil {
+		cache.store = optionAs[Store[K, V]](cache.opts.store, ctxKey, "WithStore")
+	}
+	if cache.opts.costFn != nil {
+		cache.costFn = optionAs[func(K, V) int64](cache.opts.costFn, ctxKey, "MaxCost")
+	}
+	if cache.opts.fallback != nil {
+		cache.fallbackFn = optionAs[func(K) V](cache.opts.fallback, ctxKey, "Fallback")
+	}
+	if cache.opts.clone != nil {
+		cache.cloneFn = optionAs[func(V) V](cache.opts.clone, ctxKey, "CloneOnGet")
 	}
-	ctx = context.WithValue(ctx, ctxKey, cache)
-	return ctx
+	if cache.opts.redact != nil {
+		cache.redactFn = optionAs[func(K) string](cache.opts.redact, ctxKey, "RedactKey")
+	}
+	if cache.opts.shadow != nil {
+		cache.shadowFn = optionAs[func(context.Context, K, V, V)](cache.opts.shadow, ctxKey, "Shadow")
+	}
+	for _, pin := range cache.opts.pins {
+		cache.pins = append(cache.pins, optionAs[func(K) bool](pin, ctxKey, "Pin"))
+	}
+	if cache.opts.maxEntries > 0 || cache.costFn != nil {
+		cache.lru = list.New()
+	}
+	if cache.opts.weakValues && reflect.TypeFor[V]().Kind() != reflect.Pointer {
+		panic(fmt.Sprintf("ctxcache: WeakValues option of %q needs pointer values, not %v", ctxKey, reflect.TypeFor[V]()))
+	}
+	if cache.opts.loadQuantiles {
+		cache.counters.quantiles.Store(newQuantiles())
+		cache.process.quantiles.CompareAndSwap(nil, newQuantiles())
+	}
+	if cache.opts.seed != nil {
+		cache.seedFS(ctx, cache.opts.seed)
+	}
+	return cache
+}
+
+// WithCacheIfAbsent is WithCache unless a cache of the same key and value
+// types is already installed in ctx under ctxKey, in which case it returns
+// ctx unchanged and false, so that nested middlewares share one cache
+// instead of splitting it.
+func WithCacheIfAbsent[K comparable, V any](ctx context.Context, ctxKey FuncID, f CacheFunc[K, V], opts ...Option) (context.Context, bool) {
+	if _, ok := cacheValue(ctx, ctxKey).(*cache[K, V]); ok {
+		return ctx, false
+	}
+	return WithCache(ctx, ctxKey, f, opts...), true
 }
 
 func FromContext[K comparable, V any](ctx context.Context, ctxKey FuncID, f CacheFunc[K, V]) (CacheFunc[K, V], bool) {
-	cache, ok := ctx.Value(ctxKey).(*cache[K, V])
+	cache, ok := cacheValue(ctx, ctxKey).(*cache[K, V])
 	if !ok {
 		return f, false
 	}
-	return cache.cacheLoader, true
+	sc := scopeOf(ctx)
+	return func(k K) V {
+		v, _ := cache.cacheLoader(ctx, sc, k)
+		return v
+	}, true
+}
+
+// FromContextE returns the cache installed in ctx under ctxKey as a
+// function of the key, or f bound to ctx if there is none.
+func FromContextE[K comparable, V any](ctx context.Context, ctxKey FuncID, f CacheFuncE[K, V]) (func(K) (V, error), bool) {
+	cache, ok := cacheValue(ctx, ctxKey).(*cache[K, V])
+	if !ok {
+		return func(k K) (V, error) {
+			return f(ctx, k)
+		}, false
+	}
+	sc := scopeOf(ctx)
+	return func(k K) (V, error) {
+		return cache.cacheLoader(ctx, sc, k)
+	}, true
 }
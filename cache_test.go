@@ -1 +1,81 @@
 package ctxcache
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestWithCacheE(t *testing.T) {
+	errOdd := errors.New("odd")
+	loads := 0
+	half := func(_ context.Context, n int) (int, error) {
+		loads++
+		if n%2 != 0 {
+			return 0, errOdd
+		}
+		return n / 2, nil
+	}
+	ctx := WithCacheE(context.Background(), FuncID("half"), half)
+	f, ok := FromContextE(ctx, FuncID("half"), half)
+	if !ok {
+		t.Fatal("cache not installed")
+	}
+	for i := 0; i < 2; i++ {
+		if v, err := f(4); err != nil || v != 2 {
+			t.Fatalf("f(4) = %d, %v", v, err)
+		}
+		if _, err := f(3); !errors.Is(err, errOdd) {
+			t.Fatalf("f(3) err = %v", err)
+		}
+	}
+	if loads != 3 {
+		t.Fatalf("loads = %d, want 3: errors must not be cached", loads)
+	}
+
+	g, ok := FromContextE(context.Background(), FuncID("half"), half)
+	if ok {
+		t.Fatal("unexpected cache in empty context")
+	}
+	if v, err := g(8); err != nil || v != 4 {
+		t.Fatalf("fallback g(8) = %d, %v", v, err)
+	}
+}
+
+func TestWithCacheIfAbsent(t *testing.T) {
+	calls := 0
+	load := func(n int) int {
+		calls++
+		return n
+	}
+	ctx, ok := WithCacheIfAbsent(context.Background(), FuncID("absent"), load)
+	if !ok {
+		t.Fatal("cache not installed")
+	}
+	f, _ := FromContext(ctx, FuncID("absent"), load)
+	f(1)
+
+	inner, ok := WithCacheIfAbsent(ctx, FuncID("absent"), load)
+	if ok || inner != ctx {
+		t.Fatal("existing cache replaced")
+	}
+	g, _ := FromContext(inner, FuncID("absent"), load)
+	g(1)
+	if calls != 1 {
+		t.Fatalf("cache split: calls = %d", calls)
+	}
+}
+
+func TestFuncKeyCollision(t *testing.T) {
+	type otherKey string
+	ctx := context.WithValue(context.Background(), FuncID("collide"), "foreign")
+	ctx = context.WithValue(ctx, otherKey("collide"), "foreign")
+	if Installed(ctx, FuncID("collide")) {
+		t.Fatal("foreign value taken for a cache")
+	}
+	load := func(n int) int { return n }
+	ctx = WithCache(ctx, FuncID("collide"), load)
+	if !Installed(ctx, FuncID("collide")) || ctx.Value(FuncID("collide")) != "foreign" {
+		t.Fatal("cache collided with a foreign value")
+	}
+}
@@ -0,0 +1,41 @@
+package ctxcache
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+// ErrChaos is the error injected by ChaosInject.
+var ErrChaos = errors.New("ctxcache: injected failure")
+
+// ChaosInject delays every loader call by latency and makes a fraction
+// errorRate of them fail with ErrChaos instead of calling the loader, to
+// check fallbacks and timeouts in test and staging builds. It must not be
+// used in production.
+func ChaosInject(latency time.Duration, errorRate float64) Option {
+	return func(o *options) {
+		o.chaosLatency = latency
+		o.chaosRate = errorRate
+	}
+}
+
+func chaos[K comparable, V any](load CacheFuncE[K, V], latency time.Duration, errorRate float64) CacheFuncE[K, V] {
+	return func(ctx context.Context, k K) (V, error) {
+		var zero V
+		if latency > 0 {
+			t := time.NewTimer(latency)
+			select {
+			case <-t.C:
+			case <-ctx.Done():
+				t.Stop()
+				return zero, ctx.Err()
+			}
+		}
+		if rand.Float64() < errorRate {
+			return zero, ErrChaos
+		}
+		return load(ctx, k)
+	}
+}
@@ -0,0 +1,33 @@
+package ctxcache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestChaosInject(t *testing.T) {
+	inc := func(n int) int { return n + 1 }
+	ctx := WithCache(context.Background(), FuncID("inc"), inc, ChaosInject(0, 1), Fallback(func(int) int { return -1 }))
+	f, _ := FromContext(ctx, FuncID("inc"), inc)
+	if got := f(1); got != -1 {
+		t.Fatalf("f(1) = %d, want the fallback", got)
+	}
+
+	ctx = WithCache(context.Background(), FuncID("inc"), inc, ChaosInject(time.Millisecond, 0))
+	f, _ = FromContext(ctx, FuncID("inc"), inc)
+	start := time.Now()
+	if got := f(1); got != 2 || time.Since(start) < time.Millisecond {
+		t.Fatalf("f(1) = %d after %v", got, time.Since(start))
+	}
+
+	get := func(_ context.Context, n int) (int, error) { return n, nil }
+	cctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cctx = WithCacheE(cctx, FuncID("get"), get, ChaosInject(time.Hour, 0))
+	g, _ := FromContextE(cctx, FuncID("get"), get)
+	if _, err := g(1); !errors.Is(err, context.Canceled) {
+		t.Fatalf("g(1) err = %v", err)
+	}
+}
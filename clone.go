@@ -0,0 +1,94 @@
+package ctxcache
+
+import "reflect"
+
+// CloneOnGet makes every call of the cache return clone(v) instead of the
+// cached v, so callers mutating what they get cannot corrupt what others
+// see. A nil clone uses DeepClone.
+func CloneOnGet[V any](clone func(V) V) Option {
+	if clone == nil {
+		clone = DeepClone[V]
+	}
+	return func(o *options) {
+		o.clone = clone
+	}
+}
+
+// DeepClone returns a deep copy of v made with reflection. Pointers, slices,
+// maps, arrays, interfaces and exported struct fields are copied
+// recursively; unexported fields, channels and funcs are shared. Cycles are
+// preserved.
+func DeepClone[V any](v V) V {
+	src := reflect.ValueOf(&v).Elem()
+	dst := reflect.New(src.Type()).Elem()
+	deepCopy(dst, src, make(map[pointer]reflect.Value))
+	return dst.Interface().(V)
+}
+
+// pointer identifies a copied pointer. The type is part of it, as a pointer
+// to a struct and a pointer to its first field share their address.
+type pointer struct {
+	addr uintptr
+	t    reflect.Type
+}
+
+func deepCopy(dst, src reflect.Value, seen map[pointer]reflect.Value) {
+	switch src.Kind() {
+	case reflect.Pointer:
+		if src.IsNil() {
+			return
+		}
+		id := pointer{src.Pointer(), src.Type()}
+		if p, ok := seen[id]; ok {
+			dst.Set(p)
+			return
+		}
+		p := reflect.New(src.Type().Elem())
+		seen[id] = p
+		deepCopy(p.Elem(), src.Elem(), seen)
+		dst.Set(p)
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		s := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			deepCopy(s.Index(i), src.Index(i), seen)
+		}
+		dst.Set(s)
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			deepCopy(dst.Index(i), src.Index(i), seen)
+		}
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		m := reflect.MakeMapWithSize(src.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			k := reflect.New(src.Type().Key()).Elem()
+			deepCopy(k, iter.Key(), seen)
+			v := reflect.New(src.Type().Elem()).Elem()
+			deepCopy(v, iter.Value(), seen)
+			m.SetMapIndex(k, v)
+		}
+		dst.Set(m)
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		v := reflect.New(src.Elem().Type()).Elem()
+		deepCopy(v, src.Elem(), seen)
+		dst.Set(v)
+	case reflect.Struct:
+		dst.Set(src)
+		for i := 0; i < src.NumField(); i++ {
+			if src.Type().Field(i).IsExported() {
+				deepCopy(dst.Field(i), src.Field(i), seen)
+			}
+		}
+	default:
+		dst.Set(src)
+	}
+}
@@ -0,0 +1,62 @@
+package ctxcache
+
+import (
+	"context"
+	"testing"
+)
+
+type cloneUser struct {
+	Name   string
+	Tags   []string
+	Attrs  map[string]int
+	Friend *cloneUser
+}
+
+func TestCloneOnGet(t *testing.T) {
+	load := func(name string) *cloneUser {
+		return &cloneUser{Name: name, Tags: []string{"a"}, Attrs: map[string]int{"age": 1}}
+	}
+	ctx := WithCache(context.Background(), FuncID("user"), load, CloneOnGet[*cloneUser](nil))
+	f, _ := FromContext(ctx, FuncID("user"), load)
+
+	u := f("alice")
+	u.Name = "mallory"
+	u.Tags[0] = "b"
+	u.Attrs["age"] = 2
+	again := f("alice")
+	if again.Name != "alice" || again.Tags[0] != "a" || again.Attrs["age"] != 1 {
+		t.Fatalf("cached value mutated: %+v", again)
+	}
+}
+
+func TestDeepCloneCycle(t *testing.T) {
+	u := &cloneUser{Name: "a"}
+	u.Friend = u
+	c := DeepClone(u)
+	if c == u || c.Friend != c {
+		t.Fatal("cycle not preserved")
+	}
+	var nilSlice []int
+	if DeepClone(nilSlice) != nil {
+		t.Fatal("nil slice not preserved")
+	}
+	var anyVal any = []int{1}
+	cloned := DeepClone(anyVal).([]int)
+	cloned[0] = 2
+	if anyVal.([]int)[0] != 1 {
+		t.Fatal("interface value shared")
+	}
+}
+
+func TestDeepCloneFieldPointer(t *testing.T) {
+	type inner struct{ N int }
+	type outer struct {
+		In    *inner
+		First *int
+	}
+	in := &inner{N: 1}
+	c := DeepClone(outer{In: in, First: &in.N})
+	if c.In == in || c.In.N != 1 || *c.First != 1 {
+		t.Fatalf("clone = %+v", c)
+	}
+}
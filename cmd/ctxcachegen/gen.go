@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// wrapper describes the code generated for a function.
+type wrapper struct {
+	Pkg     string
+	Name    string
+	Key     string
+	Params  []param
+	Result  string
+	Context bool
+	Error   bool
+}
+
+type param struct {
+	Name string
+	Type string
+}
+
+// generate returns the wrappers of funcs declared in the Go source src.
+func generate(filename string, src []byte, funcs []string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, 0)
+	if err != nil {
+		return nil, err
+	}
+	decls := make(map[string]*ast.FuncDecl)
+	for _, d := range file.Decls {
+		if fd, ok := d.(*ast.FuncDecl); ok && fd.Recv == nil {
+			decls[fd.Name.Name] = fd
+		}
+	}
+
+	var wrappers []wrapper
+	used := make(map[string]bool)
+	for _, name := range funcs {
+		fd, ok := decls[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("function %s not found in %s", name, filename)
+		}
+		w, err := newWrapper(fset, file.Name.Name, fd)
+		if err != nil {
+			return nil, err
+		}
+		ast.Inspect(fd.Type, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok {
+				if id, ok := sel.X.(*ast.Ident); ok {
+					used[id.Name] = true
+				}
+			}
+			return true
+		})
+		wrappers = append(wrappers, w)
+	}
+
+	// Standard library imports go first, like goimports does.
+	std := []string{`"context"`}
+	other := []string{`"github.com/alingse/ctxcache"`}
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		name := path[strings.LastIndex(path, "/")+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if !used[name] || path == "context" {
+			continue
+		}
+		imp := spec.Path.Value
+		if spec.Name != nil {
+			imp = spec.Name.Name + " " + imp
+		}
+		if strings.Contains(strings.Split(path, "/")[0], ".") {
+			other = append(other, imp)
+		} else {
+			std = append(std, imp)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, struct {
+		Pkg      string
+		Std      []string
+		Other    []string
+		Wrappers []wrapper
+	}{file.Name.Name, std, other, wrappers})
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+func newWrapper(fset *token.FileSet, pkg string, fd *ast.FuncDecl) (wrapper, error) {
+	w := wrapper{Pkg: pkg, Name: fd.Name.Name, Key: lowerFirst(fd.Name.Name) + "Key"}
+	if fd.Type.TypeParams != nil {
+		return w, fmt.Errorf("%s: generic functions are not supported", w.Name)
+	}
+	for i, field := range fd.Type.Params.List {
+		typ := expr(fset, field.Type)
+		if i == 0 && typ == "context.Context" && len(field.Names) <= 1 {
+			w.Context = true
+			continue
+		}
+		if _, ok := field.Type.(*ast.Ellipsis); ok {
+			return w, fmt.Errorf("%s: variadic functions are not supported", w.Name)
+		}
+		names := field.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{nil}
+		}
+		for _, n := range names {
+			p := param{Name: fmt.Sprintf("p%d", len(w.Params)), Type: typ}
+			if n != nil && n.Name != "_" {
+				p.Name = n.Name
+			}
+			if reserved[p.Name] {
+				p.Name += "Arg"
+			}
+			w.Params = append(w.Params, p)
+		}
+	}
+	var results []string
+	if fd.Type.Results != nil {
+		for _, field := range fd.Type.Results.List {
+			for range max(1, len(field.Names)) {
+				results = append(results, expr(fset, field.Type))
+			}
+		}
+	}
+	switch {
+	case len(results) == 1 && results[0] != "error":
+		w.Result = results[0]
+	case len(results) == 2 && results[1] == "error":
+		w.Result, w.Error = results[0], true
+	default:
+		return w, fmt.Errorf("%s: want a result and an optional error, got (%s)", w.Name, strings.Join(results, ", "))
+	}
+	return w, nil
+}
+
+// reserved are the names used by the generated code besides parameters.
+var reserved = map[string]bool{"ctx": true, "k": true, "f": true, "v": true, "ctxcache": true, "context": true}
+
+func expr(fset *token.FileSet, e ast.Expr) string {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, fset, e)
+	return buf.String()
+}
+
+func lowerFirst(s string) string {
+	r := []rune(s)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
+
+var tmpl = template.Must(template.New("").Parse(`// Code generated by ctxcachegen; DO NOT EDIT.
+
+package {{.Pkg}}
+
+import (
+{{range .Std}}	{{.}}
+{{end}}
+{{range .Other}}	{{.}}
+{{end}})
+{{range .Wrappers}}
+// {{.Key}} is the cache key of {{.Name}}.
+type {{.Key}} struct {
+{{range .Params}}	{{.Name}} {{.Type}}
+{{end}}}
+
+// {{.Name}}ID is the FuncID of the cache of {{.Name}}.
+const {{.Name}}ID ctxcache.FuncID = "{{.Pkg}}.{{.Name}}"
+
+func load{{.Name}}(ctx context.Context, k {{.Key}}) ({{.Result}}, error) {
+	return {{.Name}}({{if .Context}}ctx{{if .Params}}, {{end}}{{end}}{{range $i, $p := .Params}}{{if $i}}, {{end}}k.{{$p.Name}}{{end}}){{if not .Error}}, nil{{end}}
+}
+
+// Install{{.Name}} installs the cache of {{.Name}} in ctx.
+func Install{{.Name}}(ctx context.Context, opts ...ctxcache.Option) context.Context {
+	return ctxcache.WithCacheE(ctx, {{.Name}}ID, load{{.Name}}, opts...)
+}
+
+// Cached{{.Name}} calls {{.Name}} through the cache installed in ctx, if any.
+func Cached{{.Name}}(ctx context.Context{{range .Params}}, {{.Name}} {{.Type}}{{end}}) {{if .Error}}({{.Result}}, error){{else}}{{.Result}}{{end}} {
+	f, _ := ctxcache.FromContextE(ctx, {{.Name}}ID, load{{.Name}})
+	{{if .Error}}return f({{.Key}}{ {{- range $i, $p := .Params}}{{if $i}}, {{end}}{{$p.Name}}{{end}}}){{else}}v, _ := f({{.Key}}{ {{- range $i, $p := .Params}}{{if $i}}, {{end}}{{$p.Name}}{{end}}})
+	return v{{end}}
+}
+
+// Invalidate{{.Name}} drops the cached result of {{.Name}} for the arguments.
+func Invalidate{{.Name}}(ctx context.Context{{range .Params}}, {{.Name}} {{.Type}}{{end}}) bool {
+	return ctxcache.Invalidate(ctx, {{.Name}}ID, {{.Key}}{ {{- range $i, $p := .Params}}{{if $i}}, {{end}}{{$p.Name}}{{end}}})
+}
+{{end}}`))
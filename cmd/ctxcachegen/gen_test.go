@@ -0,0 +1,60 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+const testSrc = `package svc
+
+import (
+	"context"
+	"time"
+
+	"example.com/model"
+)
+
+func GetUser(ctx context.Context, org string, id int) (*model.User, error) { return nil, nil }
+
+func Since(t time.Time) time.Duration { return 0 }
+
+func Pair(a, _ int, f string) (string, error) { return f, nil }
+
+func Variadic(ids ...int) int { return 0 }
+
+func NoResult(id int) {}
+`
+
+func TestGenerate(t *testing.T) {
+	code, err := generate("svc.go", []byte(testSrc), []string{"GetUser", "Since", "Pair"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "svc_ctxcache.go", code, 0); err != nil {
+		t.Fatalf("generated code does not parse: %v\n%s", err, code)
+	}
+	for _, want := range []string{
+		`"example.com/model"`,
+		`"time"`,
+		"func InstallGetUser(ctx context.Context, opts ...ctxcache.Option) context.Context",
+		"func CachedGetUser(ctx context.Context, org string, id int) (*model.User, error)",
+		"return GetUser(ctx, k.org, k.id)",
+		"func CachedSince(ctx context.Context, t time.Time) time.Duration",
+		"return Since(k.t), nil",
+		"func InvalidatePair(ctx context.Context, a int, p1 int, fArg string) bool",
+	} {
+		if !strings.Contains(string(code), want) {
+			t.Errorf("missing %q in:\n%s", want, code)
+		}
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	for _, name := range []string{"Missing", "Variadic", "NoResult"} {
+		if _, err := generate("svc.go", []byte(testSrc), []string{name}); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+// Command ctxcachegen generates type-safe ctxcache wrappers for functions of
+// any signature. For every function named with -func, such as
+//
+//	func GetUser(ctx context.Context, org string, id int) (*User, error)
+//
+// it writes a key struct and the helpers InstallGetUser, CachedGetUser and
+// InvalidateGetUser. The context parameter and the error result are
+// optional; the other parameters must be comparable. Use it with
+// go:generate:
+//
+//	//go:generate go run github.com/alingse/ctxcache/cmd/ctxcachegen -func GetUser,GetOrder
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	funcs := flag.String("func", "", "comma-separated names of the functions to wrap")
+	file := flag.String("file", os.Getenv("GOFILE"), "Go file declaring the functions")
+	out := flag.String("out", "", "output file, <file>_ctxcache.go by default")
+	flag.Parse()
+	if *funcs == "" || *file == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *out == "" {
+		*out = strings.TrimSuffix(*file, ".go") + "_ctxcache.go"
+	}
+	src, err := os.ReadFile(*file)
+	if err != nil {
+		fatal(err)
+	}
+	code, err := generate(filepath.Base(*file), src, strings.Split(*funcs, ","))
+	if err != nil {
+		fatal(err)
+	}
+	if err := os.WriteFile(*out, code, 0o644); err != nil {
+		fatal(err)
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "ctxcachegen:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,150 @@
+package ctxcache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Codec serializes values for stores living outside the process.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+var (
+	// JSON is a Codec using encoding/json.
+	JSON Codec = jsonCodec{}
+	// Gob is a Codec using encoding/gob.
+	Gob Codec = gobCodec{}
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) { return json.Marshal(v) }
+
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+type gobCodec struct{}
+
+func (gobCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// Header bytes prefixed by Gzip to every payload.
+const (
+	rawPayload byte = iota
+	gzipPayload
+)
+
+// Gzip wraps c so payloads of at least threshold bytes are gzip-compressed.
+// Every payload gets a one-byte header telling whether it is compressed, so
+// the threshold can change without breaking values already stored.
+func Gzip(c Codec, threshold int) Codec {
+	return gzipCodec{codec: c, threshold: threshold}
+}
+
+type gzipCodec struct {
+	codec     Codec
+	threshold int
+}
+
+func (c gzipCodec) Marshal(v any) ([]byte, error) {
+	data, err := c.codec.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < c.threshold {
+		return append([]byte{rawPayload}, data...), nil
+	}
+	var buf bytes.Buffer
+	buf.WriteByte(gzipPayload)
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c gzipCodec) Unmarshal(data []byte, v any) error {
+	if len(data) == 0 {
+		return errors.New("ctxcache: empty gzip codec payload")
+	}
+	switch data[0] {
+	case rawPayload:
+		return c.codec.Unmarshal(data[1:], v)
+	case gzipPayload:
+		r, err := gzip.NewReader(bytes.NewReader(data[1:]))
+		if err != nil {
+			return err
+		}
+		raw, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		return c.codec.Unmarshal(raw, v)
+	default:
+		return fmt.Errorf("ctxcache: unknown gzip codec header %d", data[0])
+	}
+}
+
+// Encrypt wraps c so payloads are sealed with AES-GCM under key, which must
+// be 16, 24 or 32 bytes long. A random nonce is prefixed to every payload.
+func Encrypt(c Codec, key []byte) (Codec, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return aeadCodec{codec: c, aead: aead}, nil
+}
+
+type aeadCodec struct {
+	codec Codec
+	aead  cipher.AEAD
+}
+
+func (c aeadCodec) Marshal(v any) ([]byte, error) {
+	data, err := c.codec.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(data)+c.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return c.aead.Seal(nonce, nonce, data, nil), nil
+}
+
+func (c aeadCodec) Unmarshal(data []byte, v any) error {
+	n := c.aead.NonceSize()
+	if len(data) < n {
+		return errors.New("ctxcache: encrypted payload too short")
+	}
+	plain, err := c.aead.Open(nil, data[:n], data[n:], nil)
+	if err != nil {
+		return err
+	}
+	return c.codec.Unmarshal(plain, v)
+}
@@ -0,0 +1,56 @@
+package ctxcache
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGzip(t *testing.T) {
+	codec := Gzip(JSON, 64)
+	for _, in := range []string{"small", strings.Repeat("large ", 100)} {
+		data, err := codec.Marshal(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(in) > 64 && len(data) >= len(in) {
+			t.Fatalf("payload of %d bytes not compressed: %d", len(in), len(data))
+		}
+		var out string
+		if err := codec.Unmarshal(data, &out); err != nil {
+			t.Fatal(err)
+		}
+		if out != in {
+			t.Fatalf("got %q, want %q", out, in)
+		}
+	}
+	var out string
+	if err := codec.Unmarshal([]byte{9}, &out); err == nil {
+		t.Fatal("unknown header accepted")
+	}
+}
+
+func TestEncrypt(t *testing.T) {
+	if _, err := Encrypt(JSON, []byte("short")); err == nil {
+		t.Fatal("invalid key accepted")
+	}
+	key := []byte("0123456789abcdef")
+	codec, err := Encrypt(JSON, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := codec.Marshal("alice@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "alice") {
+		t.Fatal("payload stored in plaintext")
+	}
+	var out string
+	if err := codec.Unmarshal(data, &out); err != nil || out != "alice@example.com" {
+		t.Fatalf("got %q, %v", out, err)
+	}
+	other, _ := Encrypt(JSON, []byte("fedcba9876543210"))
+	if err := other.Unmarshal(data, &out); err == nil {
+		t.Fatal("payload opened with another key")
+	}
+}
@@ -0,0 +1,115 @@
+// Package ctxcacheconfig builds ctxcache options from configuration files,
+// so caches can be tuned per environment without code changes.
+//
+// A configuration maps FuncIDs to their settings:
+//
+//	{
+//		"user": {"ttl": "5m", "max_entries": 1000},
+//		"search": {"enabled": false}
+//	}
+//
+// The fields carry yaml tags as well, so a Config can be decoded from YAML
+// with any library honoring them.
+package ctxcacheconfig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/alingse/ctxcache"
+)
+
+// Config holds the settings of caches by FuncID.
+type Config map[ctxcache.FuncID]Cache
+
+// Cache holds the settings of a single cache. Zero values leave the
+// corresponding option unset.
+type Cache struct {
+	TTL        Duration `json:"ttl" yaml:"ttl"`
+	MaxEntries int      `json:"max_entries" yaml:"max_entries"`
+	// Policy is the eviction policy used with MaxEntries. Only "lru", the
+	// default, is supported.
+	Policy string `json:"policy" yaml:"policy"`
+	// Enabled turns the cache off when false; calls then go straight to
+	// the loader.
+	Enabled *bool `json:"enabled" yaml:"enabled"`
+}
+
+// Duration is a time.Duration written as a string such as "1m30s".
+type Duration time.Duration
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// Parse decodes and validates a JSON configuration.
+func Parse(data []byte) (Config, error) {
+	var c Config
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Load reads and parses the JSON configuration file at path.
+func Load(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Validate reports the first invalid setting of c.
+func (c Config) Validate() error {
+	for id, cache := range c {
+		switch {
+		case cache.TTL < 0:
+			return fmt.Errorf("ctxcacheconfig: %s: negative ttl %s", id, time.Duration(cache.TTL))
+		case cache.MaxEntries < 0:
+			return fmt.Errorf("ctxcacheconfig: %s: negative max_entries %d", id, cache.MaxEntries)
+		case cache.Policy != "" && cache.Policy != "lru":
+			return fmt.Errorf("ctxcacheconfig: %s: unknown policy %q", id, cache.Policy)
+		}
+	}
+	return nil
+}
+
+// Options returns the options configured for id, nil if there are none.
+func (c Config) Options(id ctxcache.FuncID) []ctxcache.Option {
+	cache, ok := c[id]
+	if !ok {
+		return nil
+	}
+	return cache.Options()
+}
+
+// Options returns the options for the settings of c.
+func (c Cache) Options() []ctxcache.Option {
+	var opts []ctxcache.Option
+	if c.TTL > 0 {
+		opts = append(opts, ctxcache.TTL(time.Duration(c.TTL)))
+	}
+	if c.MaxEntries > 0 {
+		opts = append(opts, ctxcache.MaxEntries(c.MaxEntries))
+	}
+	if c.Enabled != nil && !*c.Enabled {
+		opts = append(opts, ctxcache.Enabled(func(context.Context) bool { return false }))
+	}
+	return opts
+}
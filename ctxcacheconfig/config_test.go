@@ -0,0 +1,51 @@
+package ctxcacheconfig
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alingse/ctxcache"
+)
+
+func TestParse(t *testing.T) {
+	c, err := Parse([]byte(`{
+		"user": {"ttl": "5m", "max_entries": 2, "policy": "lru"},
+		"search": {"enabled": false}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := time.Duration(c["user"].TTL); got != 5*time.Minute {
+		t.Fatalf("ttl = %s", got)
+	}
+	if len(c.Options("user")) != 2 || len(c.Options("search")) != 1 || c.Options("missing") != nil {
+		t.Fatalf("unexpected options for %+v", c)
+	}
+
+	calls := 0
+	load := func(n int) int {
+		calls++
+		return n
+	}
+	ctx := ctxcache.WithCache(context.Background(), "search", load, c.Options("search")...)
+	f, _ := ctxcache.FromContext(ctx, "search", load)
+	f(1)
+	f(1)
+	if calls != 2 {
+		t.Fatalf("disabled cache used: calls = %d", calls)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, in := range []string{
+		`{"user": {"ttl": "soon"}}`,
+		`{"user": {"ttl": "-1s"}}`,
+		`{"user": {"max_entries": -1}}`,
+		`{"user": {"policy": "lfu"}}`,
+	} {
+		if _, err := Parse([]byte(in)); err == nil {
+			t.Errorf("%s: no error", in)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package ctxcacheconfig
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/alingse/ctxcache"
+)
+
+// Reloader holds a Config that can be replaced at runtime, typically from a
+// file or config service watcher:
+//
+//	r := ctxcacheconfig.NewReloader(cfg)
+//	watch(func(data []byte) { r.Reload(data) })
+//
+// Caches installed after a reload use the new TTL and MaxEntries; Enabled
+// is evaluated on every call, so it also applies to caches already
+// installed.
+type Reloader struct {
+	config atomic.Pointer[Config]
+}
+
+// NewReloader returns a Reloader starting with c.
+func NewReloader(c Config) *Reloader {
+	r := &Reloader{}
+	r.Store(c)
+	return r
+}
+
+// Config returns the current configuration.
+func (r *Reloader) Config() Config {
+	return *r.config.Load()
+}
+
+// Store replaces the configuration with c.
+func (r *Reloader) Store(c Config) {
+	r.config.Store(&c)
+}
+
+// Reload parses data and replaces the configuration with it. The current
+// configuration is kept if data is invalid.
+func (r *Reloader) Reload(data []byte) error {
+	c, err := Parse(data)
+	if err != nil {
+		return err
+	}
+	r.Store(c)
+	return nil
+}
+
+// Options returns the options currently configured for id.
+func (r *Reloader) Options(id ctxcache.FuncID) []ctxcache.Option {
+	cache := r.Config()[id]
+	cache.Enabled = nil
+	return append(cache.Options(), ctxcache.Enabled(func(context.Context) bool {
+		enabled := r.Config()[id].Enabled
+		return enabled == nil || *enabled
+	}))
+}
@@ -0,0 +1,34 @@
+package ctxcacheconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alingse/ctxcache"
+)
+
+func TestReloader(t *testing.T) {
+	r := NewReloader(Config{})
+	calls := 0
+	load := func(n int) int {
+		calls++
+		return n
+	}
+	ctx := ctxcache.WithCache(context.Background(), "user", load, r.Options("user")...)
+	f, _ := ctxcache.FromContext(ctx, "user", load)
+	f(1)
+	f(1)
+	if err := r.Reload([]byte(`{"user": {"enabled": false, "max_entries": 1}}`)); err != nil {
+		t.Fatal(err)
+	}
+	f(1)
+	if calls != 2 {
+		t.Fatalf("calls = %d, want 2", calls)
+	}
+	if err := r.Reload([]byte(`{"user": {"ttl": "later"}}`)); err == nil {
+		t.Fatal("invalid config accepted")
+	}
+	if r.Config()["user"].MaxEntries != 1 || len(r.Options("user")) != 2 {
+		t.Fatalf("unexpected config %+v", r.Config())
+	}
+}
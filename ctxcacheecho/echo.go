@@ -0,0 +1,19 @@
+// Package ctxcacheecho installs ctxcache caches on echo requests.
+package ctxcacheecho
+
+import (
+	"github.com/alingse/ctxcache"
+	"github.com/labstack/echo/v4"
+)
+
+// Middleware installs caches on the context of every request. Handlers find
+// them on c.Request().Context().
+func Middleware(installers ...ctxcache.Installer) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := ctxcache.WithCaches(c.Request().Context(), installers...)
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package ctxcacheecho
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alingse/ctxcache"
+	"github.com/labstack/echo/v4"
+)
+
+func TestMiddleware(t *testing.T) {
+	double := func(n int) int { return n * 2 }
+	install := ctxcache.InstallerFunc(func(ctx context.Context) context.Context {
+		return ctxcache.WithCache(ctx, "double", double)
+	})
+
+	e := echo.New()
+	e.Use(Middleware(install))
+	e.GET("/", func(c echo.Context) error {
+		if _, ok := ctxcache.FromContext(c.Request().Context(), "double", double); !ok {
+			t.Error("cache not installed on request")
+		}
+		return c.NoContent(http.StatusOK)
+	})
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+}
@@ -0,0 +1,20 @@
+// Package ctxcachegin installs ctxcache caches on gin requests.
+package ctxcachegin
+
+import (
+	"github.com/alingse/ctxcache"
+	"github.com/gin-gonic/gin"
+)
+
+// Middleware installs caches on the context of every request.
+//
+// The caches live on c.Request.Context(). Handlers passing the *gin.Context
+// itself as a context.Context only see them when the engine has
+// ContextWithFallback enabled.
+func Middleware(installers ...ctxcache.Installer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := ctxcache.WithCaches(c.Request.Context(), installers...)
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,32 @@
+package ctxcachegin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alingse/ctxcache"
+	"github.com/gin-gonic/gin"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	double := func(n int) int { return n * 2 }
+	install := ctxcache.InstallerFunc(func(ctx context.Context) context.Context {
+		return ctxcache.WithCache(ctx, "double", double)
+	})
+
+	r := gin.New()
+	r.ContextWithFallback = true
+	r.Use(Middleware(install))
+	r.GET("/", func(c *gin.Context) {
+		if _, ok := ctxcache.FromContext(c.Request.Context(), "double", double); !ok {
+			t.Error("cache not installed on request")
+		}
+		if _, ok := ctxcache.FromContext(c, "double", double); !ok {
+			t.Error("cache not visible through gin.Context")
+		}
+	})
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
@@ -0,0 +1,140 @@
+// Package ctxcachegorm provides a GORM plugin caching primary-key lookups
+// per context.
+package ctxcachegorm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/alingse/ctxcache"
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+)
+
+// Plugin serves repeated First/Take calls by primary key, such as
+// db.WithContext(ctx).First(&user, id), from the cache installed in the
+// session context. Creates, updates and deletes purge the cache.
+type Plugin struct {
+	id ctxcache.FuncID
+}
+
+var _ gorm.Plugin = (*Plugin)(nil)
+
+// New returns a Plugin caching under id.
+func New(id ctxcache.FuncID) *Plugin {
+	return &Plugin{id: id}
+}
+
+type key struct {
+	table    string
+	pk       string
+	unscoped bool
+}
+
+// record holds a model once it has been queried.
+type record struct {
+	lock  sync.Mutex
+	done  bool
+	value reflect.Value
+}
+
+func newRecord(key) *record {
+	return &record{}
+}
+
+func (p *Plugin) Name() string {
+	return "ctxcachegorm"
+}
+
+func (p *Plugin) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Query().Replace("gorm:query", p.query); err != nil {
+		return err
+	}
+	if err := db.Callback().Create().After("gorm:create").Register("ctxcachegorm:purge", p.purge); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("ctxcachegorm:purge", p.purge); err != nil {
+		return err
+	}
+	return db.Callback().Delete().After("gorm:delete").Register("ctxcachegorm:purge", p.purge)
+}
+
+// Install installs the cache of p in ctx.
+func (p *Plugin) Install(ctx context.Context) context.Context {
+	return ctxcache.WithCache(ctx, p.id, newRecord)
+}
+
+func (p *Plugin) query(db *gorm.DB) {
+	k, ok := primaryKeyLookup(db)
+	if !ok {
+		callbacks.Query(db)
+		return
+	}
+	get, ok := ctxcache.FromContext(db.Statement.Context, p.id, newRecord)
+	if !ok {
+		callbacks.Query(db)
+		return
+	}
+	r := get(k)
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	dest := db.Statement.ReflectValue
+	if r.done && r.value.Type() == dest.Type() {
+		dest.Set(r.value)
+		db.RowsAffected = 1
+		return
+	}
+	callbacks.Query(db)
+	if db.Error == nil && db.RowsAffected == 1 {
+		r.value = reflect.New(dest.Type()).Elem()
+		r.value.Set(dest)
+		r.done = true
+	}
+}
+
+func (p *Plugin) purge(db *gorm.DB) {
+	if db.Error == nil && db.Statement.Context != nil {
+		ctxcache.Purge(db.Statement.Context, p.id)
+	}
+}
+
+// primaryKeyLookup returns the cache key of db if it is a plain query of a
+// single model by primary key.
+func primaryKeyLookup(db *gorm.DB) (key, bool) {
+	stmt := db.Statement
+	if stmt.Schema == nil || stmt.SQL.Len() > 0 || stmt.ReflectValue.Kind() != reflect.Struct ||
+		len(stmt.Joins) > 0 || len(stmt.Preloads) > 0 || len(stmt.Selects) > 0 || len(stmt.Omits) > 0 ||
+		stmt.Distinct || stmt.Table != stmt.Schema.Table {
+		return key{}, false
+	}
+	for name := range stmt.Clauses {
+		switch name {
+		case "WHERE", "LIMIT", "ORDER BY":
+		default:
+			return key{}, false
+		}
+	}
+	where, ok := stmt.Clauses["WHERE"].Expression.(clause.Where)
+	if !ok || len(where.Exprs) != 1 {
+		return key{}, false
+	}
+	var pk any
+	switch expr := where.Exprs[0].(type) {
+	case clause.IN:
+		if expr.Column != clause.PrimaryColumn || len(expr.Values) != 1 {
+			return key{}, false
+		}
+		pk = expr.Values[0]
+	case clause.Eq:
+		if expr.Column != clause.PrimaryColumn {
+			return key{}, false
+		}
+		pk = expr.Value
+	default:
+		return key{}, false
+	}
+	return key{table: stmt.Table, pk: fmt.Sprint(pk), unscoped: stmt.Unscoped}, true
+}
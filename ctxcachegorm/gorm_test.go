@@ -0,0 +1,59 @@
+package ctxcachegorm
+
+import (
+	"context"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type user struct {
+	ID   int
+	Name string
+}
+
+func TestPlugin(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	plugin := New("gorm")
+	if err := db.Use(plugin); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&user{}); err != nil {
+		t.Fatal(err)
+	}
+	db.Create(&user{ID: 1, Name: "alice"})
+
+	queries := 0
+	db.Callback().Query().After("gorm:query").Register("count", func(db *gorm.DB) {
+		if db.Statement.SQL.Len() > 0 {
+			queries++
+		}
+	})
+	ctx := plugin.Install(context.Background())
+	session := db.WithContext(ctx)
+
+	for i := 0; i < 3; i++ {
+		var u user
+		if err := session.First(&u, 1).Error; err != nil || u.Name != "alice" {
+			t.Fatalf("First = %+v, %v", u, err)
+		}
+	}
+	var all []user
+	session.Find(&all)
+	if len(all) != 1 {
+		t.Fatalf("Find = %v", all)
+	}
+
+	session.Model(&user{ID: 1}).Update("name", "bob")
+	var u user
+	if err := session.First(&u, 1).Error; err != nil || u.Name != "bob" {
+		t.Fatalf("First after update = %+v, %v", u, err)
+	}
+	if queries != 3 {
+		t.Fatalf("queries = %d, want 3", queries)
+	}
+}
@@ -0,0 +1,122 @@
+// Package ctxcachegql provides per-request dataloaders for GraphQL servers
+// such as gqlgen, backed by ctxcache.
+package ctxcachegql
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/alingse/ctxcache"
+	"github.com/alingse/ctxcache/ctxcachehttp"
+)
+
+// Middleware installs loaders on the context of every request, so resolvers
+// share them for the lifetime of the request:
+//
+//	srv := handler.NewDefaultServer(schema)
+//	http.Handle("/query", ctxcachegql.Middleware(userLoader, postLoader)(srv))
+func Middleware(loaders ...ctxcache.Installer) func(http.Handler) http.Handler {
+	return ctxcachehttp.Middleware(loaders...)
+}
+
+// Option configures a Loader.
+type Option func(*options)
+
+type options struct {
+	wait     time.Duration
+	maxBatch int
+	cache    []ctxcache.Option
+}
+
+// WithWait sets how long a Loader collects keys before fetching them,
+// 1ms by default.
+func WithWait(d time.Duration) Option {
+	return func(o *options) {
+		o.wait = d
+	}
+}
+
+// WithMaxBatch sets the maximum number of keys fetched at once, 100 by
+// default.
+func WithMaxBatch(n int) Option {
+	return func(o *options) {
+		o.maxBatch = n
+	}
+}
+
+// WithCacheOptions passes opts to ctxcache.WithCache when the loader is
+// installed.
+func WithCacheOptions(opts ...ctxcache.Option) Option {
+	return func(o *options) {
+		o.cache = append(o.cache, opts...)
+	}
+}
+
+// Loader batches and caches the loading of models of type T by their ID
+// within a request.
+type Loader[ID comparable, T any] struct {
+	id    ctxcache.FuncID
+	fetch ctxcache.BatchFunc[ID, T]
+	opts  options
+}
+
+// NewLoader returns a Loader fetching models with fetch. IDs missing from
+// the returned map load as the zero T.
+func NewLoader[ID comparable, T any](name string, fetch func(ids []ID) map[ID]T, opts ...Option) *Loader[ID, T] {
+	l := &Loader[ID, T]{
+		id:    ctxcache.FuncID(name),
+		fetch: fetch,
+		opts:  options{wait: time.Millisecond, maxBatch: 100},
+	}
+	for _, opt := range opts {
+		opt(&l.opts)
+	}
+	return l
+}
+
+// ByID returns a Loader for fetch functions returning a slice of models,
+// such as a SQL "WHERE id IN (...)" query. idOf extracts the ID of a model.
+func ByID[ID comparable, T any](name string, fetch func(ids []ID) []T, idOf func(T) ID, opts ...Option) *Loader[ID, T] {
+	return NewLoader(name, func(ids []ID) map[ID]T {
+		models := fetch(ids)
+		byID := make(map[ID]T, len(models))
+		for _, m := range models {
+			byID[idOf(m)] = m
+		}
+		return byID
+	}, opts...)
+}
+
+// Install installs a fresh batching cache for l in ctx.
+func (l *Loader[ID, T]) Install(ctx context.Context) context.Context {
+	return ctxcache.WithCache(ctx, l.id, ctxcache.Batch(l.fetch, l.opts.wait, l.opts.maxBatch), l.opts.cache...)
+}
+
+// Load returns the model with the given id. Without an installed cache the
+// model is fetched on its own.
+func (l *Loader[ID, T]) Load(ctx context.Context, id ID) T {
+	f, _ := ctxcache.FromContext(ctx, l.id, l.fetchOne)
+	return f(id)
+}
+
+// LoadMany returns the models with the given ids, fetched in as few batches
+// as possible.
+func (l *Loader[ID, T]) LoadMany(ctx context.Context, ids []ID) []T {
+	models := make([]T, len(ids))
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i int, id ID) {
+			defer wg.Done()
+			models[i] = l.Load(ctx, id)
+		}(i, id)
+	}
+	wg.Wait()
+	return models
+}
+
+func (l *Loader[ID, T]) fetchOne(id ID) T {
+	return l.fetch([]ID{id})[id]
+}
@@ -0,0 +1,43 @@
+package ctxcachegql
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+type user struct {
+	ID   int
+	Name string
+}
+
+func TestMiddleware(t *testing.T) {
+	var fetches atomic.Int32
+	users := ByID("user", func(ids []int) []user {
+		fetches.Add(1)
+		res := make([]user, 0, len(ids))
+		for _, id := range ids {
+			res = append(res, user{ID: id, Name: "u"})
+		}
+		return res
+	}, func(u user) int { return u.ID })
+
+	h := Middleware(users)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := users.LoadMany(r.Context(), []int{1, 2, 3, 2})
+		if got[1].ID != 2 || got[3].ID != 2 {
+			t.Errorf("LoadMany = %v", got)
+		}
+		if users.Load(r.Context(), 3).ID != 3 {
+			t.Error("Load(3) failed")
+		}
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if n := fetches.Load(); n != 1 {
+		t.Fatalf("fetches = %d, want 1", n)
+	}
+
+	if users.Load(httptest.NewRequest(http.MethodGet, "/", nil).Context(), 7).ID != 7 {
+		t.Fatal("uninstalled Load failed")
+	}
+}
@@ -0,0 +1,98 @@
+// Package ctxcachegroupcache serves the shared tier of ctxcache from
+// groupcache peers. Each key is owned by one peer, picked by the consistent
+// hashing of the registered groupcache.PeerPicker such as an HTTPPool; the
+// owner loads misses from the origin and the other peers fetch from it, so
+// hot keys do not hit the origin from every replica.
+package ctxcachegroupcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/alingse/ctxcache"
+	"github.com/golang/groupcache"
+)
+
+// ErrImmutable is returned by Store.Delete: groupcache entries cannot be
+// removed or overwritten.
+var ErrImmutable = errors.New("ctxcachegroupcache: entries are immutable")
+
+// Option configures a Store.
+type Option func(*options)
+
+type options struct {
+	codec ctxcache.Codec
+}
+
+// WithCodec sets the codec used to serialize values, ctxcache.JSON by default.
+func WithCodec(codec ctxcache.Codec) Option {
+	return func(o *options) {
+		o.codec = codec
+	}
+}
+
+// Store is a ctxcache.Store reading values from a groupcache group. Keys are
+// formatted with fmt.Sprint.
+type Store[K comparable, V any] struct {
+	group *groupcache.Group
+	opts  options
+}
+
+var _ ctxcache.Store[string, int] = (*Store[string, int])(nil)
+
+// New creates the groupcache group name, holding at most cacheBytes on this
+// peer, and returns a Store over it. The owner of a key loads it with load;
+// parse turns the formatted key back into a K. Like groupcache.NewGroup, New
+// panics if a group named name already exists.
+func New[K comparable, V any](name string, cacheBytes int64, load ctxcache.CacheFuncE[K, V], parse func(string) (K, error), opts ...Option) *Store[K, V] {
+	s := &Store[K, V]{opts: options{codec: ctxcache.JSON}}
+	for _, opt := range opts {
+		opt(&s.opts)
+	}
+	s.group = groupcache.NewGroup(name, cacheBytes, groupcache.GetterFunc(func(ctx context.Context, key string, dest groupcache.Sink) error {
+		k, err := parse(key)
+		if err != nil {
+			return err
+		}
+		v, err := load(ctx, k)
+		if err != nil {
+			return err
+		}
+		data, err := s.opts.codec.Marshal(v)
+		if err != nil {
+			return err
+		}
+		return dest.SetBytes(data)
+	}))
+	return s
+}
+
+// Get returns the value of key, fetched from its owner peer or loaded if
+// this peer owns it. It reports a miss only if the load fails, with its
+// error. The local cache then calls its own loader, so a key whose origin
+// load fails is loaded a second time, by this peer; pass a loader that does
+// not reach the origin, such as one returning an error, to avoid it.
+func (s *Store[K, V]) Get(ctx context.Context, key K) (V, bool, error) {
+	var v V
+	var data []byte
+	if err := s.group.Get(ctx, fmt.Sprint(key), groupcache.AllocatingByteSliceSink(&data)); err != nil {
+		return v, false, err
+	}
+	if err := s.opts.codec.Unmarshal(data, &v); err != nil {
+		return v, false, err
+	}
+	return v, true, nil
+}
+
+// Set does nothing: values are filled by the owner of their key.
+func (s *Store[K, V]) Set(context.Context, K, V) error {
+	return nil
+}
+
+// Delete returns ErrImmutable. Values cannot be replaced, only superseded by
+// new keys, such as keys carrying a version. ctxcache.WithVersion does not
+// help, as contexts with a version token do not read the Store.
+func (s *Store[K, V]) Delete(context.Context, K) error {
+	return ErrImmutable
+}
@@ -0,0 +1,38 @@
+package ctxcachegroupcache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/alingse/ctxcache"
+)
+
+func TestStore(t *testing.T) {
+	origin := 0
+	square := func(_ context.Context, n int) (int, error) {
+		origin++
+		return n * n, nil
+	}
+	store := New("square", 1<<20, square, strconv.Atoi)
+
+	loads := 0
+	load := func(ctx context.Context, n int) (int, error) {
+		loads++
+		return square(ctx, n)
+	}
+	for range 2 {
+		ctx := ctxcache.WithCacheE(context.Background(), "square", load, ctxcache.WithStore[int, int](store))
+		f, _ := ctxcache.FromContextE(ctx, "square", load)
+		if v, err := f(3); err != nil || v != 9 {
+			t.Fatalf("f(3) = %d, %v", v, err)
+		}
+	}
+	if origin != 1 || loads != 0 {
+		t.Fatalf("origin = %d, loads = %d, want the group to load once", origin, loads)
+	}
+	if err := store.Delete(context.Background(), 3); !errors.Is(err, ErrImmutable) {
+		t.Fatalf("Delete err = %v", err)
+	}
+}
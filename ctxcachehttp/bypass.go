@@ -0,0 +1,26 @@
+package ctxcachehttp
+
+import (
+	"net/http"
+
+	"github.com/alingse/ctxcache"
+)
+
+// BypassHeader is the request header read by BypassFromHeader.
+const BypassHeader = "Ctxcache-Bypass"
+
+// BypassFromHeader makes requests with the header name set to "1" bypass
+// the reads of every cache, see ctxcache.BypassReads. Anyone able to send
+// the header can force loads, so only use it behind authentication:
+//
+//	r.With(requireSupport, ctxcachehttp.BypassFromHeader(ctxcachehttp.BypassHeader))
+func BypassFromHeader(name string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get(name) == "1" {
+				r = r.WithContext(ctxcache.BypassReads(r.Context()))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
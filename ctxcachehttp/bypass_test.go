@@ -0,0 +1,36 @@
+package ctxcachehttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alingse/ctxcache"
+)
+
+func TestBypassFromHeader(t *testing.T) {
+	loads := 0
+	double := func(n int) int {
+		loads++
+		return n * 2
+	}
+	install := ctxcache.InstallerFunc(func(ctx context.Context) context.Context {
+		return ctxcache.WithCache(ctx, "double", double)
+	})
+	h := Middleware(install)(BypassFromHeader(BypassHeader)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, _ := ctxcache.FromContext(r.Context(), "double", double)
+		f(1)
+		f(1)
+	})))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if loads != 1 {
+		t.Fatalf("loads = %d, want 1", loads)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(BypassHeader, "1")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if loads != 3 {
+		t.Fatalf("loads = %d, want 3 with reads bypassed", loads)
+	}
+}
@@ -0,0 +1,22 @@
+// Package ctxcachehttp integrates ctxcache with net/http.
+package ctxcachehttp
+
+import (
+	"net/http"
+
+	"github.com/alingse/ctxcache"
+)
+
+// Middleware installs caches on the context of every request. It works with
+// any router built on net/http middleware, such as chi:
+//
+//	r := chi.NewRouter()
+//	r.Use(ctxcachehttp.Middleware(userCache, orderCache))
+func Middleware(installers ...ctxcache.Installer) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := ctxcache.WithCaches(r.Context(), installers...)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package ctxcachehttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alingse/ctxcache"
+)
+
+func TestMiddleware(t *testing.T) {
+	loads := 0
+	double := func(n int) int {
+		loads++
+		return n * 2
+	}
+	install := ctxcache.InstallerFunc(func(ctx context.Context) context.Context {
+		return ctxcache.WithCache(ctx, "double", double)
+	})
+	h := Middleware(install)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, ok := ctxcache.FromContext(r.Context(), "double", double)
+		if !ok {
+			t.Error("cache not installed")
+		}
+		f(1)
+		f(1)
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if loads != 2 {
+		t.Fatalf("loads = %d, want one per request", loads)
+	}
+}
@@ -0,0 +1,143 @@
+package ctxcachehttp
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+
+	"github.com/alingse/ctxcache"
+)
+
+// Transport memoizes 200 responses to GET requests per request context, so
+// code paths fetching the same resource within a request share one upstream
+// call. Responses are keyed by URL and by the Accept, Authorization and
+// Cookie headers. Requests with a Range header, and requests whose context
+// has no cache installed, pass through.
+//
+// Concurrent requests for the same key wait for the first one to complete,
+// or for their context to be done.
+type Transport struct {
+	// Base is the underlying RoundTripper, http.DefaultTransport if nil.
+	Base http.RoundTripper
+	// ID is the FuncID the cache is installed under.
+	ID ctxcache.FuncID
+	// MaxBodySize is the size of the largest body cached, 1 MiB if zero.
+	// Larger responses are returned uncached.
+	MaxBodySize int64
+}
+
+// defaultMaxBodySize is the MaxBodySize of a zero Transport.
+const defaultMaxBodySize = 1 << 20
+
+// response holds a GET response once it has been fetched.
+type response struct {
+	// sem is held while fetching the response.
+	sem        chan struct{}
+	done       bool
+	status     string
+	statusCode int
+	proto      string
+	protoMajor int
+	protoMinor int
+	header     http.Header
+	body       []byte
+}
+
+func newResponse(string) *response {
+	return &response{sem: make(chan struct{}, 1)}
+}
+
+// cacheKey returns the key of the response to req. Credentials are hashed,
+// so that they do not show in logs or dumps of the cache.
+func cacheKey(req *http.Request) string {
+	key := req.URL.String() + " accept=" + req.Header.Get("Accept")
+	auth, cookie := req.Header.Get("Authorization"), req.Header.Get("Cookie")
+	if auth != "" || cookie != "" {
+		sum := sha256.Sum256([]byte(auth + "\n" + cookie))
+		key += " auth=" + hex.EncodeToString(sum[:8])
+	}
+	return key
+}
+
+// Install installs the response cache of t in ctx.
+func (t *Transport) Install(ctx context.Context) context.Context {
+	return ctxcache.WithCache(ctx, t.ID, newResponse)
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+func (t *Transport) maxBodySize() int64 {
+	if t.MaxBodySize > 0 {
+		return t.MaxBodySize
+	}
+	return defaultMaxBodySize
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return t.base().RoundTrip(req)
+	}
+	get, ok := ctxcache.FromContext(req.Context(), t.ID, newResponse)
+	if !ok {
+		return t.base().RoundTrip(req)
+	}
+	r := get(cacheKey(req))
+	select {
+	case r.sem <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	defer func() { <-r.sem }()
+	if r.done {
+		return r.clone(req), nil
+	}
+
+	resp, err := t.base().RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, t.maxBodySize()+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if int64(len(body)) > t.maxBodySize() {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp, nil
+	}
+	resp.Body.Close()
+	r.status = resp.Status
+	r.statusCode = resp.StatusCode
+	r.proto = resp.Proto
+	r.protoMajor = resp.ProtoMajor
+	r.protoMinor = resp.ProtoMinor
+	r.header = resp.Header.Clone()
+	r.body = body
+	r.done = true
+	return r.clone(req), nil
+}
+
+func (r *response) clone(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        r.status,
+		StatusCode:    r.statusCode,
+		Proto:         r.proto,
+		ProtoMajor:    r.protoMajor,
+		ProtoMinor:    r.protoMinor,
+		Header:        r.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(r.body)),
+		ContentLength: int64(len(r.body)),
+		Request:       req,
+	}
+}
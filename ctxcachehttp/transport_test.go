@@ -0,0 +1,90 @@
+package ctxcachehttp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestTransport(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		switch {
+		case r.URL.Path == "/missing":
+			http.NotFound(w, r)
+		case r.Header.Get("Range") != "":
+			w.WriteHeader(http.StatusPartialContent)
+			io.WriteString(w, "/")
+		case r.URL.Path == "/large":
+			io.WriteString(w, strings.Repeat("x", 16))
+		default:
+			io.WriteString(w, r.URL.Path+" "+r.Header.Get("Accept")+r.Header.Get("Authorization"))
+		}
+	}))
+	defer srv.Close()
+
+	transport := &Transport{ID: "http"}
+	client := &http.Client{Transport: transport}
+	ctx := transport.Install(context.Background())
+
+	transport.MaxBodySize = 8
+	get := func(ctx context.Context, path string, header ...string) string {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+path, nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+	for i := 0; i < 3; i++ {
+		if got := get(ctx, "/a"); got != "/a " {
+			t.Fatalf("body = %q", got)
+		}
+	}
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/a", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.Status != "200 OK" || resp.Proto != "HTTP/1.1" || resp.ProtoMajor != 1 || resp.ProtoMinor != 1 {
+		t.Fatalf("cached response = %q %q %d.%d", resp.Status, resp.Proto, resp.ProtoMajor, resp.ProtoMinor)
+	}
+	get(ctx, "/missing")
+	get(ctx, "/missing")
+	get(context.Background(), "/a")
+	if n := calls.Load(); n != 4 {
+		t.Fatalf("calls = %d, want 4", n)
+	}
+
+	if got := get(ctx, "/a", "Range", "bytes=0-0"); got != "/" {
+		t.Fatalf("range body = %q", got)
+	}
+	if got := get(ctx, "/a"); got != "/a " {
+		t.Fatalf("body after range request = %q", got)
+	}
+	if got := get(ctx, "/a", "Accept", "text/plain"); got != "/a text/plain" {
+		t.Fatalf("body with Accept = %q", got)
+	}
+	if got := get(ctx, "/a", "Authorization", "Bearer x"); got != "/a Bearer x" {
+		t.Fatalf("body with Authorization = %q", got)
+	}
+	for range 2 {
+		if got := get(ctx, "/large"); len(got) != 16 {
+			t.Fatalf("large body = %q", got)
+		}
+	}
+	if n := calls.Load(); n != 9 {
+		t.Fatalf("calls = %d, want 9", n)
+	}
+}
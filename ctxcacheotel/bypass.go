@@ -0,0 +1,21 @@
+package ctxcacheotel
+
+import (
+	"context"
+
+	"github.com/alingse/ctxcache"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+// BypassKey is the baggage key read by BypassFromBaggage.
+const BypassKey = "ctxcache-bypass"
+
+// BypassFromBaggage returns ctxcache.BypassReads(ctx) if the baggage of ctx
+// has BypassKey set to "1", so the signal propagates to downstream services
+// with the trace. Otherwise it returns ctx.
+func BypassFromBaggage(ctx context.Context) context.Context {
+	if baggage.FromContext(ctx).Member(BypassKey).Value() == "1" {
+		return ctxcache.BypassReads(ctx)
+	}
+	return ctx
+}
@@ -0,0 +1,38 @@
+package ctxcacheotel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alingse/ctxcache"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+func TestBypassFromBaggage(t *testing.T) {
+	loads := 0
+	double := func(n int) int {
+		loads++
+		return n * 2
+	}
+	ctx := ctxcache.WithCache(context.Background(), "double", double)
+	member, err := baggage.NewMember(BypassKey, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bag, err := baggage.New(member)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, _ := ctxcache.FromContext(BypassFromBaggage(ctx), "double", double)
+	f(1)
+	f(1)
+	if loads != 1 {
+		t.Fatalf("loads = %d without baggage, want 1", loads)
+	}
+	g, _ := ctxcache.FromContext(BypassFromBaggage(baggage.ContextWithBaggage(ctx, bag)), "double", double)
+	g(1)
+	if loads != 2 {
+		t.Fatalf("loads = %d with baggage, want 2", loads)
+	}
+}
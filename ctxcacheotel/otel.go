@@ -0,0 +1,67 @@
+// Package ctxcacheotel records ctxcache metrics with OpenTelemetry.
+package ctxcacheotel
+
+import (
+	"context"
+
+	"github.com/alingse/ctxcache"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const scope = "github.com/alingse/ctxcache/ctxcacheotel"
+
+// Observer records the events of caches as OpenTelemetry metrics:
+//
+//	ctxcache.hits, ctxcache.misses and ctxcache.evictions counters, and
+//	the ctxcache.load.duration histogram in seconds,
+//
+// all with a func_id attribute, plus an error attribute for loads.
+type Observer struct {
+	hits      metric.Int64Counter
+	misses    metric.Int64Counter
+	evictions metric.Int64Counter
+	loads     metric.Float64Histogram
+}
+
+var _ ctxcache.Observer = (*Observer)(nil)
+
+// New returns an Observer creating its instruments with mp. Install it on
+// caches with ctxcache.Observe.
+func New(mp metric.MeterProvider) (*Observer, error) {
+	meter := mp.Meter(scope)
+	o := &Observer{}
+	var err error
+	if o.hits, err = meter.Int64Counter("ctxcache.hits",
+		metric.WithDescription("Cache hits.")); err != nil {
+		return nil, err
+	}
+	if o.misses, err = meter.Int64Counter("ctxcache.misses",
+		metric.WithDescription("Cache misses.")); err != nil {
+		return nil, err
+	}
+	if o.evictions, err = meter.Int64Counter("ctxcache.evictions",
+		metric.WithDescription("Entries evicted or expired.")); err != nil {
+		return nil, err
+	}
+	if o.loads, err = meter.Float64Histogram("ctxcache.load.duration",
+		metric.WithDescription("Duration of loader calls."), metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func (o *Observer) Observe(ctx context.Context, ev ctxcache.Event) {
+	id := attribute.String("func_id", string(ev.FuncID))
+	switch ev.Type {
+	case ctxcache.EventHit:
+		o.hits.Add(ctx, 1, metric.WithAttributes(id))
+	case ctxcache.EventMiss:
+		o.misses.Add(ctx, 1, metric.WithAttributes(id))
+	case ctxcache.EventEvict:
+		o.evictions.Add(ctx, 1, metric.WithAttributes(id))
+	case ctxcache.EventLoad:
+		o.loads.Record(ctx, ev.Duration.Seconds(),
+			metric.WithAttributes(id, attribute.Bool("error", ev.Err != nil)))
+	}
+}
@@ -0,0 +1,47 @@
+package ctxcacheotel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alingse/ctxcache"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestObserver(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	obs, err := New(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	load := func(n int) int { return n }
+	ctx := ctxcache.WithCache(context.Background(), "otel", load, ctxcache.Observe(obs))
+	f, _ := ctxcache.FromContext(ctx, "otel", load)
+	f(1)
+	f(1)
+	f(1)
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]int64)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				for _, dp := range data.DataPoints {
+					got[m.Name] += dp.Value
+				}
+			case metricdata.Histogram[float64]:
+				for _, dp := range data.DataPoints {
+					got[m.Name] += int64(dp.Count)
+				}
+			}
+		}
+	}
+	if got["ctxcache.hits"] != 2 || got["ctxcache.misses"] != 1 || got["ctxcache.load.duration"] != 1 {
+		t.Fatalf("metrics = %v", got)
+	}
+}
@@ -0,0 +1,72 @@
+package ctxcacheredis
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/alingse/ctxcache"
+	"github.com/redis/go-redis/v9"
+)
+
+// PubSubClient is the part of a redis client used by Invalidator.
+// *redis.Client and *redis.ClusterClient implement it.
+type PubSubClient interface {
+	Publish(ctx context.Context, channel string, message interface{}) *redis.IntCmd
+	Subscribe(ctx context.Context, channels ...string) *redis.PubSub
+}
+
+// Invalidator is a ctxcache.Invalidator over a redis pub/sub channel.
+type Invalidator struct {
+	client  PubSubClient
+	channel string
+}
+
+var _ ctxcache.Invalidator = (*Invalidator)(nil)
+
+// NewInvalidator returns an Invalidator publishing on channel with client.
+func NewInvalidator(client PubSubClient, channel string) *Invalidator {
+	return &Invalidator{client: client, channel: channel}
+}
+
+type invalidation struct {
+	ID  ctxcache.FuncID `json:"id"`
+	Key string          `json:"key"`
+}
+
+func (i *Invalidator) Publish(ctx context.Context, id ctxcache.FuncID, key string) error {
+	data, err := json.Marshal(invalidation{ID: id, Key: key})
+	if err != nil {
+		return err
+	}
+	return i.client.Publish(ctx, i.channel, data).Err()
+}
+
+// Subscribe calls fn with every invalidation published on the channel until
+// ctx is done, returning ctx.Err(). Malformed messages are skipped.
+func (i *Invalidator) Subscribe(ctx context.Context, fn func(id ctxcache.FuncID, key string)) error {
+	ps := i.client.Subscribe(ctx, i.channel)
+	defer ps.Close()
+	if _, err := ps.Receive(ctx); err != nil {
+		return err
+	}
+	ch := ps.Channel()
+	for {
+		select {
+		case msg, ok := <-ch:
+			if !ok {
+				return redis.ErrClosed
+			}
+			handle(msg.Payload, fn)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func handle(payload string, fn func(id ctxcache.FuncID, key string)) {
+	var inv invalidation
+	if err := json.Unmarshal([]byte(payload), &inv); err != nil {
+		return
+	}
+	fn(inv.ID, inv.Key)
+}
@@ -0,0 +1,41 @@
+package ctxcacheredis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alingse/ctxcache"
+	"github.com/redis/go-redis/v9"
+)
+
+type fakePubSub struct {
+	PubSubClient
+	published map[string][]string
+}
+
+func (f *fakePubSub) Publish(_ context.Context, channel string, message interface{}) *redis.IntCmd {
+	f.published[channel] = append(f.published[channel], string(message.([]byte)))
+	return redis.NewIntResult(1, nil)
+}
+
+func TestInvalidator(t *testing.T) {
+	client := &fakePubSub{published: map[string][]string{}}
+	inv := NewInvalidator(client, "invalidations")
+	if err := inv.Publish(context.Background(), "user", "42"); err != nil {
+		t.Fatal(err)
+	}
+	msgs := client.published["invalidations"]
+	if len(msgs) != 1 {
+		t.Fatalf("published %v", client.published)
+	}
+
+	var got []string
+	fn := func(id ctxcache.FuncID, key string) {
+		got = append(got, string(id)+":"+key)
+	}
+	handle(msgs[0], fn)
+	handle("not json", fn)
+	if len(got) != 1 || got[0] != "user:42" {
+		t.Fatalf("handled %v", got)
+	}
+}
@@ -0,0 +1,96 @@
+// Package ctxcacheredis implements ctxcache.Store and ctxcache.Invalidator
+// over go-redis.
+package ctxcacheredis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alingse/ctxcache"
+	"github.com/redis/go-redis/v9"
+)
+
+// Option configures a Store.
+type Option func(*options)
+
+type options struct {
+	prefix string
+	ttl    time.Duration
+	codec  ctxcache.Codec
+}
+
+// WithPrefix prepends prefix to every redis key written by the store.
+func WithPrefix(prefix string) Option {
+	return func(o *options) {
+		o.prefix = prefix
+	}
+}
+
+// WithTTL sets the expiration of values written by the store. Zero means
+// values never expire.
+func WithTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.ttl = ttl
+	}
+}
+
+// WithCodec sets the codec used to serialize values, ctxcache.JSON by default.
+func WithCodec(codec ctxcache.Codec) Option {
+	return func(o *options) {
+		o.codec = codec
+	}
+}
+
+// Store is a ctxcache.Store keeping values in redis. Keys are formatted with
+// fmt.Sprint after the prefix.
+type Store[K comparable, V any] struct {
+	client redis.Cmdable
+	opts   options
+}
+
+var _ ctxcache.Store[string, int] = (*Store[string, int])(nil)
+
+// New returns a Store using client.
+func New[K comparable, V any](client redis.Cmdable, opts ...Option) *Store[K, V] {
+	s := &Store[K, V]{
+		client: client,
+		opts:   options{codec: ctxcache.JSON},
+	}
+	for _, opt := range opts {
+		opt(&s.opts)
+	}
+	return s
+}
+
+func (s *Store[K, V]) key(k K) string {
+	return s.opts.prefix + fmt.Sprint(k)
+}
+
+func (s *Store[K, V]) Get(ctx context.Context, key K) (V, bool, error) {
+	var v V
+	data, err := s.client.Get(ctx, s.key(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return v, false, nil
+	}
+	if err != nil {
+		return v, false, err
+	}
+	if err := s.opts.codec.Unmarshal(data, &v); err != nil {
+		return v, false, err
+	}
+	return v, true, nil
+}
+
+func (s *Store[K, V]) Set(ctx context.Context, key K, value V) error {
+	data, err := s.opts.codec.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, s.key(key), data, s.opts.ttl).Err()
+}
+
+func (s *Store[K, V]) Delete(ctx context.Context, key K) error {
+	return s.client.Del(ctx, s.key(key)).Err()
+}
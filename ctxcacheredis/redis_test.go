@@ -0,0 +1,64 @@
+package ctxcacheredis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alingse/ctxcache"
+	"github.com/redis/go-redis/v9"
+)
+
+type fakeClient struct {
+	redis.Cmdable
+	data map[string]string
+}
+
+func (f *fakeClient) Get(_ context.Context, key string) *redis.StringCmd {
+	v, ok := f.data[key]
+	if !ok {
+		return redis.NewStringResult("", redis.Nil)
+	}
+	return redis.NewStringResult(v, nil)
+}
+
+func (f *fakeClient) Set(_ context.Context, key string, value interface{}, _ time.Duration) *redis.StatusCmd {
+	f.data[key] = string(value.([]byte))
+	return redis.NewStatusResult("OK", nil)
+}
+
+func (f *fakeClient) Del(_ context.Context, keys ...string) *redis.IntCmd {
+	for _, key := range keys {
+		delete(f.data, key)
+	}
+	return redis.NewIntResult(int64(len(keys)), nil)
+}
+
+func TestStore(t *testing.T) {
+	client := &fakeClient{data: map[string]string{}}
+	store := New[int, []string](client, WithPrefix("user:"), WithCodec(ctxcache.Gob))
+
+	loads := 0
+	load := func(n int) []string {
+		loads++
+		return []string{"a", "b"}
+	}
+	for i := 0; i < 2; i++ {
+		ctx := ctxcache.WithCache(context.Background(), "user", load, ctxcache.WithStore[int, []string](store))
+		f, _ := ctxcache.FromContext(ctx, "user", load)
+		if got := f(42); len(got) != 2 {
+			t.Fatalf("f(42) = %v", got)
+		}
+	}
+	if loads != 1 {
+		t.Fatalf("loads = %d, want 1", loads)
+	}
+	if _, ok := client.data["user:42"]; !ok {
+		t.Fatal("value not written with prefix")
+	}
+
+	store.Delete(context.Background(), 42)
+	if _, ok, _ := store.Get(context.Background(), 42); ok {
+		t.Fatal("value not deleted")
+	}
+}
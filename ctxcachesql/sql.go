@@ -0,0 +1,138 @@
+// Package ctxcachesql memoizes database/sql single-row queries per context.
+package ctxcachesql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/maphash"
+	"reflect"
+	"sync"
+
+	"github.com/alingse/ctxcache"
+)
+
+// Querier is implemented by *sql.DB, *sql.Tx and *sql.Conn.
+type Querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// DB memoizes QueryRowContext results of a Querier in the contexts it is
+// installed in, keyed by the query and a hash of its arguments.
+type DB struct {
+	q  Querier
+	id ctxcache.FuncID
+}
+
+// Wrap returns a DB querying q and caching under id.
+func Wrap(q Querier, id ctxcache.FuncID) *DB {
+	return &DB{q: q, id: id}
+}
+
+type queryKey struct {
+	query string
+	args  uint64
+}
+
+var seed = maphash.MakeSeed()
+
+func newQueryKey(query string, args []any) queryKey {
+	return queryKey{query: query, args: maphash.String(seed, fmt.Sprintf("%#v", args))}
+}
+
+// result holds the scanned values of a row once it has been queried.
+type result struct {
+	lock   sync.Mutex
+	done   bool
+	values []any
+	err    error
+}
+
+func newResult(queryKey) *result {
+	return &result{}
+}
+
+// Install installs the query cache of db in ctx.
+func (db *DB) Install(ctx context.Context) context.Context {
+	return ctxcache.WithCache(ctx, db.id, newResult)
+}
+
+// QueryRowContext returns a Row whose Scan is served from the cache of ctx
+// when the same query with the same arguments was scanned before.
+func (db *DB) QueryRowContext(ctx context.Context, query string, args ...any) *Row {
+	return &Row{ctx: ctx, db: db, query: query, args: args}
+}
+
+// Row is the result of DB.QueryRowContext.
+type Row struct {
+	ctx   context.Context
+	db    *DB
+	query string
+	args  []any
+}
+
+// Scan copies the columns of the row into dest like sql.Row.Scan.
+// Successful scans and sql.ErrNoRows are cached; other errors are not.
+func (r *Row) Scan(dest ...any) error {
+	get, ok := ctxcache.FromContext(r.ctx, r.db.id, newResult)
+	if !ok {
+		return r.db.q.QueryRowContext(r.ctx, r.query, r.args...).Scan(dest...)
+	}
+	res := get(newQueryKey(r.query, r.args))
+	res.lock.Lock()
+	defer res.lock.Unlock()
+	if res.done {
+		if res.err != nil {
+			return res.err
+		}
+		if copyValues(dest, res.values) {
+			return nil
+		}
+		return r.db.q.QueryRowContext(r.ctx, r.query, r.args...).Scan(dest...)
+	}
+
+	err := r.db.q.QueryRowContext(r.ctx, r.query, r.args...).Scan(dest...)
+	switch {
+	case err == nil:
+		res.values = make([]any, len(dest))
+		for i, d := range dest {
+			res.values[i] = reflect.ValueOf(d).Elem().Interface()
+		}
+		res.done = true
+	case errors.Is(err, sql.ErrNoRows):
+		res.err = err
+		res.done = true
+	}
+	return err
+}
+
+// copyValues stores values into the pointers in dest, reporting false if
+// they do not match.
+func copyValues(dest, values []any) bool {
+	if len(dest) != len(values) {
+		return false
+	}
+	for i, d := range dest {
+		dv := reflect.ValueOf(d)
+		if dv.Kind() != reflect.Pointer || dv.IsNil() {
+			return false
+		}
+		if values[i] == nil {
+			continue
+		}
+		v := reflect.ValueOf(values[i])
+		if !v.Type().AssignableTo(dv.Elem().Type()) {
+			return false
+		}
+	}
+	for i, d := range dest {
+		elem := reflect.ValueOf(d).Elem()
+		if values[i] == nil {
+			elem.SetZero()
+			continue
+		}
+		elem.Set(reflect.ValueOf(values[i]))
+	}
+	return true
+}
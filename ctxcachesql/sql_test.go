@@ -0,0 +1,97 @@
+package ctxcachesql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+)
+
+var queries atomic.Int32
+
+// fakeDriver answers "SELECT name" queries with a single row holding its
+// first argument, and anything else with no rows.
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{}, nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{query}, nil }
+func (fakeConn) Close() error                              { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                 { return nil, errors.New("unsupported") }
+
+type fakeStmt struct{ query string }
+
+func (fakeStmt) Close() error  { return nil }
+func (fakeStmt) NumInput() int { return -1 }
+func (fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("unsupported")
+}
+
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	queries.Add(1)
+	if s.query != "SELECT name" {
+		return &fakeRows{}, nil
+	}
+	return &fakeRows{values: args}, nil
+}
+
+type fakeRows struct {
+	values []driver.Value
+	read   bool
+}
+
+func (r *fakeRows) Columns() []string { return make([]string, len(r.values)) }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.read || r.values == nil {
+		return io.EOF
+	}
+	r.read = true
+	copy(dest, r.values)
+	return nil
+}
+
+func init() {
+	sql.Register("ctxcachesql_fake", fakeDriver{})
+}
+
+func TestQueryRowContext(t *testing.T) {
+	conn, err := sql.Open("ctxcachesql_fake", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	db := Wrap(conn, "sql")
+	ctx := db.Install(context.Background())
+	queries.Store(0)
+
+	for i := 0; i < 3; i++ {
+		var name string
+		if err := db.QueryRowContext(ctx, "SELECT name", "alice").Scan(&name); err != nil || name != "alice" {
+			t.Fatalf("Scan = %q, %v", name, err)
+		}
+	}
+	var name string
+	if err := db.QueryRowContext(ctx, "SELECT name", "bob").Scan(&name); err != nil || name != "bob" {
+		t.Fatalf("Scan = %q, %v", name, err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := db.QueryRowContext(ctx, "SELECT none").Scan(&name); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("Scan err = %v", err)
+		}
+	}
+	if n := queries.Load(); n != 3 {
+		t.Fatalf("queries = %d, want 3", n)
+	}
+
+	var n int
+	if err := db.QueryRowContext(ctx, "SELECT name", "alice").Scan(&n); err == nil {
+		t.Fatal("scanning a string into an int should fail")
+	}
+}
@@ -0,0 +1,164 @@
+// Package ctxcachestatsd sends ctxcache metrics to StatsD, with the tag
+// extension of Datadog's DogStatsD.
+//
+//	client, err := ctxcachestatsd.New("127.0.0.1:8125")
+//	ctx = ctxcache.WithCache(ctx, "user", loadUser, ctxcache.Observe(ctxcache.ReportTo(client)))
+package ctxcachestatsd
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alingse/ctxcache"
+)
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithPrefix prepends prefix to every metric name.
+func WithPrefix(prefix string) Option {
+	return func(c *Client) {
+		c.prefix = prefix
+	}
+}
+
+// WithTags adds tags, in name:value form, to every metric.
+func WithTags(tags ...string) Option {
+	return func(c *Client) {
+		c.tags = append(c.tags, tags...)
+	}
+}
+
+// WithFlushInterval sets how often buffered metrics are sent, every 100ms
+// by default. Non-positive intervals keep the default.
+func WithFlushInterval(d time.Duration) Option {
+	return func(c *Client) {
+		if d > 0 {
+			c.interval = d
+		}
+	}
+}
+
+// defaultFlushInterval is the flush interval of a Client without
+// WithFlushInterval.
+const defaultFlushInterval = 100 * time.Millisecond
+
+// maxPacket is the size of the datagrams the metrics are batched into,
+// fitting a typical MTU.
+const maxPacket = 1432
+
+// Client is a ctxcache.Reporter buffering metrics and sending them in
+// batches from a background goroutine, so reporting never blocks the
+// caches. Metrics are dropped when the buffer is full, and write errors are
+// ignored, as is usual with StatsD.
+type Client struct {
+	conn     net.Conn
+	prefix   string
+	tags     []string
+	interval time.Duration
+
+	metrics   chan string
+	closing   chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+var _ ctxcache.Reporter = (*Client)(nil)
+
+// New returns a Client sending to the StatsD server at addr.
+func New(addr string, opts ...Option) (*Client, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{
+		conn:     conn,
+		interval: defaultFlushInterval,
+		metrics:  make(chan string, 4096),
+		closing:  make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	go c.run()
+	return c, nil
+}
+
+func (c *Client) Count(name string, value int64, tags []string) {
+	c.send(name, fmt.Sprintf("%d|c", value), tags)
+}
+
+func (c *Client) Timing(name string, d time.Duration, tags []string) {
+	c.send(name, fmt.Sprintf("%g|ms", float64(d)/float64(time.Millisecond)), tags)
+}
+
+// Close sends the buffered metrics and closes the connection of c.
+func (c *Client) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.closing)
+	})
+	<-c.done
+	return c.conn.Close()
+}
+
+// run batches the metrics into datagrams of up to maxPacket bytes, sent when
+// full and every interval.
+func (c *Client) run() {
+	defer close(c.done)
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	var buf []byte
+	flush := func() {
+		if len(buf) > 0 {
+			c.conn.Write(buf)
+			buf = buf[:0]
+		}
+	}
+	add := func(m string) {
+		if len(buf) > 0 && len(buf)+1+len(m) > maxPacket {
+			flush()
+		}
+		if len(buf) > 0 {
+			buf = append(buf, '\n')
+		}
+		buf = append(buf, m...)
+	}
+	for {
+		select {
+		case m := <-c.metrics:
+			add(m)
+		case <-ticker.C:
+			flush()
+		case <-c.closing:
+			for {
+				select {
+				case m := <-c.metrics:
+					add(m)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (c *Client) send(name, value string, tags []string) {
+	var b strings.Builder
+	b.WriteString(c.prefix)
+	b.WriteString(name)
+	b.WriteByte(':')
+	b.WriteString(value)
+	if len(c.tags)+len(tags) > 0 {
+		b.WriteString("|#")
+		b.WriteString(strings.Join(append(append([]string(nil), c.tags...), tags...), ","))
+	}
+	select {
+	case c.metrics <- b.String():
+	default:
+	}
+}
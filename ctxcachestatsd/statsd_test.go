@@ -0,0 +1,89 @@
+package ctxcachestatsd
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alingse/ctxcache"
+)
+
+func TestClient(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	client, err := New(server.LocalAddr().String(), WithPrefix("app."), WithTags("env:test"), WithFlushInterval(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	load := func(n int) int { return n }
+	ctx := ctxcache.WithCache(context.Background(), "statsd", load, ctxcache.Observe(ctxcache.ReportTo(client)))
+	f, _ := ctxcache.FromContext(ctx, "statsd", load)
+	f(1)
+	f(1)
+
+	var got []string
+	buf := make([]byte, maxPacket)
+	server.SetReadDeadline(time.Now().Add(time.Second))
+	for len(got) < 3 {
+		n, _, err := server.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, strings.Split(string(buf[:n]), "\n")...)
+	}
+	if got[0] != "app.ctxcache.misses:1|c|#env:test,func_id:statsd" ||
+		!strings.HasPrefix(got[1], "app.ctxcache.load:") || !strings.HasSuffix(got[1], "|ms|#env:test,func_id:statsd,error:false") ||
+		got[2] != "app.ctxcache.hits:1|c|#env:test,func_id:statsd" {
+		t.Fatalf("metrics = %q", got)
+	}
+}
+
+func TestClientBatches(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	client, err := New(server.LocalAddr().String(), WithFlushInterval(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 100 {
+		client.Count("hits", 1, nil)
+	}
+	client.Close()
+
+	lines := 0
+	buf := make([]byte, maxPacket)
+	server.SetReadDeadline(time.Now().Add(time.Second))
+	for lines < 100 {
+		n, _, err := server.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("received %d metrics: %v", lines, err)
+		}
+		if n > maxPacket {
+			t.Fatalf("datagram of %d bytes", n)
+		}
+		lines += strings.Count(string(buf[:n]), "\n") + 1
+	}
+}
+
+func TestFlushIntervalDefault(t *testing.T) {
+	for _, d := range []time.Duration{0, -time.Second} {
+		client, err := New("127.0.0.1:8125", WithFlushInterval(d))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if client.interval != defaultFlushInterval {
+			t.Errorf("interval = %v for WithFlushInterval(%v)", client.interval, d)
+		}
+		client.Close()
+	}
+}
@@ -0,0 +1,73 @@
+// Package ctxcachetest provides helpers for testing code using ctxcache.
+package ctxcachetest
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/alingse/ctxcache"
+)
+
+// UpdateEnv is the environment variable that makes Golden rewrite golden
+// files instead of comparing against them, when set to a non-empty value.
+const UpdateEnv = "CTXCACHE_UPDATE_GOLDEN"
+
+type entry[V any] struct {
+	Key   json.RawMessage `json:"key"`
+	Value V               `json:"value"`
+}
+
+// Snapshot serializes the entries cached in ctx under id as indented JSON,
+// sorted by the JSON encoding of their keys, shorter first so numbers sort
+// naturally, making the output deterministic.
+func Snapshot[K comparable, V any](ctx context.Context, id ctxcache.FuncID) ([]byte, error) {
+	entries := []entry[V]{}
+	for k, v := range ctxcache.Entries[K, V](ctx, id) {
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry[V]{Key: key, Value: v})
+	}
+	slices.SortFunc(entries, func(a, b entry[V]) int {
+		return cmp.Or(len(a.Key)-len(b.Key), bytes.Compare(a.Key, b.Key))
+	})
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// Golden compares the Snapshot of the cache installed in ctx under id with
+// the golden file name, failing t on a difference. With UpdateEnv set, it
+// writes the snapshot to name instead.
+func Golden[K comparable, V any](t testing.TB, ctx context.Context, id ctxcache.FuncID, name string) {
+	t.Helper()
+	got, err := Snapshot[K, V](ctx, id)
+	if err != nil {
+		t.Fatalf("snapshot of %s: %v", id, err)
+	}
+	if os.Getenv(UpdateEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatalf("%v; set %s=1 to create it", err, UpdateEnv)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("cache %s differs from %s; set %s=1 to update it\ngot:\n%s\nwant:\n%s", id, name, UpdateEnv, got, want)
+	}
+}
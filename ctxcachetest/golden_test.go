@@ -0,0 +1,29 @@
+package ctxcachetest
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/alingse/ctxcache"
+)
+
+func TestGolden(t *testing.T) {
+	square := func(n int) int { return n * n }
+	ctx := ctxcache.WithCache(context.Background(), "square", square)
+	f, _ := ctxcache.FromContext(ctx, "square", square)
+	for _, n := range []int{10, 3, 2} {
+		f(n)
+	}
+	Golden[int, int](t, ctx, "square", filepath.Join("testdata", "square.golden"))
+
+	f(4)
+	got, err := Snapshot[int, int](ctx, "square")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "[\n  {\n    \"key\": 2,\n    \"value\": 4\n  },\n  {\n    \"key\": 3,\n    \"value\": 9\n  },\n  {\n    \"key\": 4,\n    \"value\": 16\n  },\n  {\n    \"key\": 10,\n    \"value\": 100\n  }\n]\n"
+	if string(got) != want {
+		t.Fatalf("Snapshot = %s", got)
+	}
+}
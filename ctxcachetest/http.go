@@ -0,0 +1,57 @@
+package ctxcachetest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/alingse/ctxcache"
+)
+
+// spyBuffer is the number of events a Spy holds between two reads.
+const spyBuffer = 4096
+
+// NewRequestWithCaches returns an httptest request whose context has the
+// caches of defs installed, and a Spy recording their events, for unit
+// tests of handlers.
+func NewRequestWithCaches(method, url string, defs ...ctxcache.Installer) (*http.Request, *Spy) {
+	r := httptest.NewRequest(method, url, nil)
+	ctx := ctxcache.WithCaches(r.Context(), defs...)
+	return r.WithContext(ctx), &Spy{events: ctxcache.Events(ctx, spyBuffer)}
+}
+
+// Spy records the events of the caches of a request. Events past the
+// buffer of 4096 between two reads are dropped.
+type Spy struct {
+	events <-chan ctxcache.Event
+	lock   sync.Mutex
+	seen   []ctxcache.Event
+}
+
+// Events returns the events recorded so far, in order.
+func (s *Spy) Events() []ctxcache.Event {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for {
+		select {
+		case e, ok := <-s.events:
+			if !ok {
+				return s.seen
+			}
+			s.seen = append(s.seen, e)
+		default:
+			return s.seen
+		}
+	}
+}
+
+// Count returns the number of events of type typ recorded for the cache id.
+func (s *Spy) Count(id ctxcache.FuncID, typ ctxcache.EventType) int {
+	n := 0
+	for _, e := range s.Events() {
+		if e.FuncID == id && e.Type == typ {
+			n++
+		}
+	}
+	return n
+}
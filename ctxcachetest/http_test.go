@@ -0,0 +1,30 @@
+package ctxcachetest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/alingse/ctxcache"
+)
+
+func TestNewRequestWithCaches(t *testing.T) {
+	square := ctxcache.Define("square", func(n int) int { return n * n })
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f := square.FromContext(r.Context())
+		n, _ := strconv.Atoi(r.URL.Query().Get("n"))
+		fmt.Fprint(w, f(n)+f(n))
+	})
+
+	r, spy := NewRequestWithCaches(http.MethodGet, "/?n=3", square)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Body.String() != "18" {
+		t.Fatalf("body = %q", w.Body.String())
+	}
+	if spy.Count("square", ctxcache.EventLoad) != 1 || spy.Count("square", ctxcache.EventHit) != 1 {
+		t.Fatalf("events = %v", spy.Events())
+	}
+}
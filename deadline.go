@@ -0,0 +1,48 @@
+package ctxcache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNearDeadline is returned by caches installed with SkipNearDeadline for
+// the misses too close to their context deadline to call the loader.
+var ErrNearDeadline = errors.New("ctxcache: too close to deadline")
+
+// SkipNearDeadline makes misses skip the loader when the deadline of their
+// context is less than d away, as the load would likely not finish in time.
+// They return the expired value of the key if it is still held, else the
+// Fallback value, else ErrNearDeadline.
+func SkipNearDeadline(d time.Duration) Option {
+	return func(o *options) {
+		o.skipNear = d
+	}
+}
+
+func nearDeadline(ctx context.Context, d time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return ok && time.Until(deadline) < d
+}
+
+// skip returns the value of a miss of k that does not call the loader.
+func (c *cache[K, V]) skip(ctx context.Context, k K) (V, error) {
+	if v, ok := c.stale(k, versionOf(ctx)); ok {
+		return v, nil
+	}
+	var zero V
+	return c.fallback(k, zero, ErrNearDeadline)
+}
+
+// stale returns the value of k even if it has expired, unless it is not held
+// anymore or is an error.
+func (c *cache[K, V]) stale(k K, version string) (V, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	e, ok := c.data.load(k)
+	if !ok || e.version() != version || e.err() != nil {
+		var zero V
+		return zero, false
+	}
+	return e.get()
+}
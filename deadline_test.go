@@ -0,0 +1,44 @@
+package ctxcache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSkipNearDeadline(t *testing.T) {
+	loads := 0
+	load := func(_ context.Context, n int) (int, error) {
+		loads++
+		return n * n, nil
+	}
+	ctx := WithCacheE(context.Background(), FuncID("square"), load, SkipNearDeadline(time.Second), TTL(time.Millisecond))
+	f, _ := FromContextE(ctx, FuncID("square"), load)
+	if v, err := f(2); v != 4 || err != nil {
+		t.Fatalf("f(2) = %d, %v", v, err)
+	}
+
+	near, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	g, _ := FromContextE(near, FuncID("square"), load)
+	if _, err := g(3); !errors.Is(err, ErrNearDeadline) {
+		t.Fatalf("g(3) err = %v, want ErrNearDeadline", err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	if v, err := g(2); v != 4 || err != nil {
+		t.Fatalf("g(2) = %d, %v, want the expired value", v, err)
+	}
+	if loads != 1 {
+		t.Fatalf("loads = %d, want 1", loads)
+	}
+
+	fallback := func(int) int { return -1 }
+	ctx = WithCacheE(context.Background(), FuncID("square"), load, SkipNearDeadline(time.Second), Fallback(fallback))
+	near, cancel = context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	h, _ := FromContextE(near, FuncID("square"), load)
+	if v, err := h(3); v != -1 || err != nil {
+		t.Fatalf("h(3) = %d, %v, want the fallback", v, err)
+	}
+}
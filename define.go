@@ -0,0 +1,50 @@
+package ctxcache
+
+import "context"
+
+// Definition bundles a FuncID, its loader and default options, so that
+// install and call sites only name the definition:
+//
+//	var userCache = ctxcache.Define("user", loadUser, ctxcache.TTL(time.Minute))
+//
+//	ctx = userCache.Install(ctx)
+//	user := userCache.FromContext(ctx)(id)
+type Definition[K comparable, V any] struct {
+	id   FuncID
+	f    CacheFunc[K, V]
+	opts []Option
+}
+
+var _ Installer = (*Definition[int, int])(nil)
+
+// Define returns the Definition of the cache of f under id with opts as
+// default options.
+func Define[K comparable, V any](id FuncID, f CacheFunc[K, V], opts ...Option) *Definition[K, V] {
+	return &Definition[K, V]{id: id, f: f, opts: opts}
+}
+
+// ID returns the FuncID of d.
+func (d *Definition[K, V]) ID() FuncID {
+	return d.id
+}
+
+// With returns a copy of d whose options are its defaults followed by
+// opts, which override them.
+func (d *Definition[K, V]) With(opts ...Option) *Definition[K, V] {
+	merged := make([]Option, 0, len(d.opts)+len(opts))
+	merged = append(merged, d.opts...)
+	merged = append(merged, opts...)
+	return &Definition[K, V]{id: d.id, f: d.f, opts: merged}
+}
+
+// Install installs the cache of d in ctx.
+func (d *Definition[K, V]) Install(ctx context.Context) context.Context {
+	return WithCache(ctx, d.id, d.f, d.opts...)
+}
+
+// FromContext returns the cache of d installed in ctx, or its loader if
+// there is none.
+func (d *Definition[K, V]) FromContext(ctx context.Context) CacheFunc[K, V] {
+	f, _ := FromContext(ctx, d.id, d.f)
+	return f
+}
@@ -0,0 +1,41 @@
+package ctxcache
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDefine(t *testing.T) {
+	calls := 0
+	def := Define(FuncID("define"), func(n int) int {
+		calls++
+		return n
+	}, MaxEntries(1))
+
+	ctx := def.Install(context.Background())
+	f := def.FromContext(ctx)
+	f(1)
+	f(2)
+	f(1)
+	if calls != 3 {
+		t.Fatalf("default MaxEntries not applied: calls = %d", calls)
+	}
+
+	calls = 0
+	ctx = def.With(MaxEntries(2)).Install(context.Background())
+	f = def.FromContext(ctx)
+	f(1)
+	f(2)
+	f(1)
+	if calls != 2 {
+		t.Fatalf("override not applied: calls = %d", calls)
+	}
+
+	calls = 0
+	f = def.FromContext(context.Background())
+	f(1)
+	f(1)
+	if calls != 2 {
+		t.Fatalf("uninstalled definition cached: calls = %d", calls)
+	}
+}
@@ -0,0 +1,117 @@
+package ctxcache
+
+import "context"
+
+// Dep names the entry of key in the cache installed under ID.
+type Dep struct {
+	ID  FuncID
+	Key any
+}
+
+type loadingKey struct{}
+
+// loading is an entry being loaded, seen from its loader's context.
+type loading struct {
+	c   anyCache
+	key any
+}
+
+func (c *cache[K, V]) loading(ctx context.Context, k K) context.Context {
+	return context.WithValue(ctx, loadingKey{}, loading{c: c, key: k})
+}
+
+// DependsOn records, from inside a loader, that the entry being loaded is
+// derived from deps: invalidating any of them also invalidates it, across
+// caches and transitively. Deps on caches not installed in ctx, or with keys
+// of the wrong type, are ignored. Outside a loader DependsOn does nothing.
+func DependsOn(ctx context.Context, deps ...Dep) {
+	l, ok := ctx.Value(loadingKey{}).(loading)
+	if !ok {
+		return
+	}
+	for _, d := range deps {
+		if c, ok := cacheValue(ctx, d.ID).(anyCache); ok && c.addDependent(d.Key, l) {
+			l.c.addParent(l.key, loading{c: c, key: d.Key})
+		}
+	}
+}
+
+// addDependent links l to key, reporting whether key has the key type of
+// the cache.
+func (c *cache[K, V]) addDependent(key any, l loading) bool {
+	k, ok := key.(K)
+	if !ok {
+		return false
+	}
+	c.deps.Lock()
+	defer c.deps.Unlock()
+	if c.dependents == nil {
+		c.dependents = make(map[K]map[loading]struct{})
+	}
+	if c.dependents[k] == nil {
+		c.dependents[k] = make(map[loading]struct{})
+	}
+	c.dependents[k][l] = struct{}{}
+	return true
+}
+
+// addParent records that the entry of key is derived from p, so that the
+// link is dropped with the entry.
+func (c *cache[K, V]) addParent(key any, p loading) {
+	k := key.(K)
+	c.deps.Lock()
+	defer c.deps.Unlock()
+	if c.parents == nil {
+		c.parents = make(map[K]map[loading]struct{})
+	}
+	if c.parents[k] == nil {
+		c.parents[k] = make(map[loading]struct{})
+	}
+	c.parents[k][p] = struct{}{}
+}
+
+// dropDependent removes the link of l to key.
+func (c *cache[K, V]) dropDependent(key any, l loading) {
+	k := key.(K)
+	c.deps.Lock()
+	defer c.deps.Unlock()
+	delete(c.dependents[k], l)
+	if len(c.dependents[k]) == 0 {
+		delete(c.dependents, k)
+	}
+}
+
+// takeDependents removes and returns the entries derived from k.
+func (c *cache[K, V]) takeDependents(k K) map[loading]struct{} {
+	c.deps.Lock()
+	defer c.deps.Unlock()
+	dependents := c.dependents[k]
+	delete(c.dependents, k)
+	return dependents
+}
+
+// unlink drops the links DependsOn made from the entry of k to the entries
+// it is derived from, once the entry is removed.
+func (c *cache[K, V]) unlink(k K) {
+	c.deps.Lock()
+	parents := c.parents[k]
+	delete(c.parents, k)
+	c.deps.Unlock()
+	for p := range parents {
+		p.c.dropDependent(p.key, loading{c: c, key: k})
+	}
+}
+
+// unlinkAll drops the links of every entry, as unlink, and forgets the
+// entries derived from the cache.
+func (c *cache[K, V]) unlinkAll() {
+	c.deps.Lock()
+	parents := c.parents
+	c.parents, c.dependents = nil, nil
+	c.deps.Unlock()
+	for k, ps := range parents {
+		for p := range ps {
+			p.c.dropDependent(p.key, loading{c: c, key: k})
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package ctxcache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDependsOn(t *testing.T) {
+	loads := map[string]int{}
+	user := func(_ context.Context, id string) (string, error) {
+		loads["user:"+id]++
+		return "user " + id, nil
+	}
+	perms := func(ctx context.Context, id string) (string, error) {
+		loads["perms:"+id]++
+		DependsOn(ctx, Dep{ID: FuncID("user"), Key: id})
+		return "perms of " + id, nil
+	}
+	roles := func(ctx context.Context, id string) (string, error) {
+		loads["roles:"+id]++
+		DependsOn(ctx, Dep{ID: FuncID("perms"), Key: id})
+		return "roles of " + id, nil
+	}
+	ctx := WithCacheE(context.Background(), FuncID("user"), user)
+	ctx = WithCacheE(ctx, FuncID("perms"), perms)
+	ctx = WithCacheE(ctx, FuncID("roles"), roles)
+	u, _ := FromContextE(ctx, FuncID("user"), user)
+	p, _ := FromContextE(ctx, FuncID("perms"), perms)
+	r, _ := FromContextE(ctx, FuncID("roles"), roles)
+	for range 2 {
+		for _, id := range []string{"42", "7"} {
+			u(id)
+			p(id)
+			r(id)
+		}
+	}
+
+	Invalidate(ctx, FuncID("user"), "42")
+	for _, id := range []string{"42", "7"} {
+		u(id)
+		p(id)
+		r(id)
+	}
+	want := map[string]int{
+		"user:42": 2, "perms:42": 2, "roles:42": 2,
+		"user:7": 1, "perms:7": 1, "roles:7": 1,
+	}
+	for k, n := range want {
+		if loads[k] != n {
+			t.Errorf("loads[%s] = %d, want %d", k, loads[k], n)
+		}
+	}
+	DependsOn(ctx, Dep{ID: FuncID("user"), Key: "42"})
+}
+
+func TestDependsOnLinks(t *testing.T) {
+	user := func(_ context.Context, id string) (string, error) {
+		return "user " + id, nil
+	}
+	perms := func(ctx context.Context, id string) (string, error) {
+		DependsOn(ctx, Dep{ID: FuncID("user"), Key: id})
+		return "perms of " + id, nil
+	}
+	ctx := WithCacheE(context.Background(), FuncID("user"), user)
+	ctx = WithCacheE(ctx, FuncID("perms"), perms, MaxEntries(1), TTL(time.Nanosecond))
+	p, _ := FromContextE(ctx, FuncID("perms"), perms)
+	pc := cacheValue(ctx, FuncID("perms")).(*cache[string, string])
+	uc := cacheValue(ctx, FuncID("user")).(*cache[string, string])
+	for range 3 {
+		p("42") // reloads, as entries expire at once
+	}
+	if n := len(uc.dependents["42"]); n != 1 {
+		t.Fatalf("%d links after reloads", n)
+	}
+	p("7") // evicts 42
+	if _, ok := uc.dependents["42"]; ok || len(pc.parents) != 1 {
+		t.Fatalf("links of evicted entry kept: %v, %v", uc.dependents, pc.parents)
+	}
+	Invalidate(ctx, FuncID("perms"), "7")
+	if len(uc.dependents) != 0 || len(pc.parents) != 0 {
+		t.Fatalf("links of invalidated entry kept: %v, %v", uc.dependents, pc.parents)
+	}
+}
@@ -0,0 +1,68 @@
+package ctxcache
+
+import (
+	"context"
+	"encoding/json"
+)
+
+type cacheDump struct {
+	FuncID    FuncID      `json:"func_id"`
+	Namespace string      `json:"namespace,omitempty"`
+	Stats     Stats       `json:"stats"`
+	Entries   []entryDump `json:"entries"`
+}
+
+type entryDump struct {
+	Key   json.RawMessage `json:"key"`
+	Value json.RawMessage `json:"value,omitempty"`
+	Error string          `json:"error,omitempty"`
+	EntryMeta
+}
+
+// DumpJSON serializes every cache installed in ctx, and each of its
+// Namespace partitions, with its stats and entries. Keys and values that
+// cannot be marshaled are reported by their marshaling error instead, and
+// entries cached by CacheErrors by their error. Keys of caches with
+// RedactKey are dumped redacted.
+func DumpJSON(ctx context.Context) ([]byte, error) {
+	dumps := []cacheDump{}
+	visibleCaches(ctx, withPartitions(func(c anyCache) {
+		dumps = append(dumps, c.dump())
+	}))
+	return json.Marshal(dumps)
+}
+
+func (c *cache[K, V]) dump() cacheDump {
+	d := cacheDump{FuncID: c.id, Namespace: c.ns, Stats: c.stats(), Entries: []entryDump{}}
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	for k, e := range c.data.all() {
+		ed := entryDump{EntryMeta: e.meta()}
+		var key []byte
+		var err error
+		if c.redactFn != nil {
+			key, err = json.Marshal(c.redactFn(k))
+		} else {
+			key, err = json.Marshal(k)
+		}
+		if err != nil {
+			ed.Key, _ = json.Marshal(err.Error())
+			ed.Error = err.Error()
+			d.Entries = append(d.Entries, ed)
+			continue
+		}
+		ed.Key = key
+		if e.err() != nil {
+			ed.Error = e.err().Error()
+			d.Entries = append(d.Entries, ed)
+			continue
+		}
+		v, _ := e.get()
+		if ed.Value, err = json.Marshal(v); err != nil {
+			ed.Value = nil
+			ed.Error = err.Error()
+		}
+		d.Entries = append(d.Entries, ed)
+	}
+	return d
+}
@@ -0,0 +1,46 @@
+package ctxcache
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestDumpJSON(t *testing.T) {
+	double := func(n int) int { return n * 2 }
+	makeChan := func(n int) chan int { return make(chan int, n) }
+	ctx := WithCache(context.Background(), FuncID("double"), double)
+	ctx = WithCache(ctx, FuncID("chan"), makeChan)
+	f, _ := FromContext(ctx, FuncID("double"), double)
+	f(1)
+	f(1)
+	g, _ := FromContext(ctx, FuncID("chan"), makeChan)
+	g(1)
+
+	data, err := DumpJSON(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dumps []struct {
+		FuncID  string `json:"func_id"`
+		Stats   Stats  `json:"stats"`
+		Entries []struct {
+			Key   int    `json:"key"`
+			Value *int   `json:"value"`
+			Error string `json:"error"`
+		} `json:"entries"`
+	}
+	if err := json.Unmarshal(data, &dumps); err != nil {
+		t.Fatal(err)
+	}
+	if len(dumps) != 2 {
+		t.Fatalf("dump = %s", data)
+	}
+	ch, dbl := dumps[0], dumps[1]
+	if dbl.FuncID != "double" || dbl.Stats.Hits != 1 || dbl.Stats.Loads != 1 || *dbl.Entries[0].Value != 2 {
+		t.Fatalf("dump = %s", data)
+	}
+	if ch.FuncID != "chan" || ch.Entries[0].Error == "" {
+		t.Fatalf("dump = %s", data)
+	}
+}
@@ -0,0 +1,165 @@
+package ctxcache
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// EventType is the kind of an Event.
+type EventType int
+
+const (
+	EventHit EventType = iota
+	EventMiss
+	EventLoad
+	EventEvict
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventHit:
+		return "hit"
+	case EventMiss:
+		return "miss"
+	case EventLoad:
+		return "load"
+	case EventEvict:
+		return "evict"
+	}
+	return "unknown"
+}
+
+// Event describes an operation of a cache.
+type Event struct {
+	Type   EventType
+	FuncID FuncID
+	// Key is the key, or its redacted form with RedactKey.
+	Key any
+	// Duration and Err are those of the loader call for EventLoad.
+	Duration time.Duration
+	Err      error
+}
+
+// Events returns a channel receiving the events of every cache installed
+// in ctx so far, until ctx is done, when the channel is closed. Events are
+// dropped rather than blocking the caches when the channel buffer of size
+// buffer is full.
+func Events(ctx context.Context, buffer int) <-chan Event {
+	ch := make(chan Event, buffer)
+	var caches []anyCache
+	allCaches(ctx, func(c anyCache) {
+		c.subscribe(ch)
+		caches = append(caches, c)
+	})
+	context.AfterFunc(ctx, func() {
+		for _, c := range caches {
+			c.unsubscribe(ch)
+		}
+		close(ch)
+	})
+	return ch
+}
+
+func (c *cache[K, V]) subscribe(ch chan Event) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	var subs []chan Event
+	if p := c.subscribers.Load(); p != nil {
+		subs = slices.Clone(*p)
+	}
+	subs = append(subs, ch)
+	c.subscribers.Store(&subs)
+}
+
+// unsubscribe removes ch from the subscribers. Once it returns, ch receives
+// no more events.
+func (c *cache[K, V]) unsubscribe(ch chan Event) {
+	c.lock.Lock()
+	subs := slices.DeleteFunc(slices.Clone(*c.subscribers.Load()), func(s chan Event) bool { return s == ch })
+	c.subscribers.Store(&subs)
+	c.lock.Unlock()
+	// Wait for the emits that loaded the previous subscribers.
+	c.emitting.Lock()
+	c.emitting.Unlock()
+}
+
+// Observer receives the events of the caches installed with Observe. It is
+// called synchronously by the cache, without its lock held, and must be
+// safe for concurrent use.
+type Observer interface {
+	Observe(ctx context.Context, ev Event)
+}
+
+// ObserverFunc adapts a function to an Observer.
+type ObserverFunc func(ctx context.Context, ev Event)
+
+func (f ObserverFunc) Observe(ctx context.Context, ev Event) {
+	f(ctx, ev)
+}
+
+// Observe passes every event of the cache to o, for metrics and tracing
+// integrations. It can be given several times.
+func Observe(o Observer) Option {
+	return func(opts *options) {
+		opts.observers = append(opts.observers, o)
+	}
+}
+
+func (c *cache[K, V]) emit(ctx context.Context, typ EventType, k K, d time.Duration, err error) {
+	// Partitions send to the subscribers of their cache.
+	sub := c
+	if c.parent != nil {
+		sub = c.parent
+	}
+	p := sub.subscribers.Load()
+	subscribed := p != nil && len(*p) > 0
+	if !subscribed && len(c.opts.observers) == 0 {
+		return
+	}
+	ev := Event{Type: typ, FuncID: c.id, Key: k, Duration: d, Err: err}
+	if c.redactFn != nil {
+		ev.Key = c.redactFn(k)
+	}
+	for _, o := range c.opts.observers {
+		o.Observe(ctx, ev)
+	}
+	if !subscribed {
+		return
+	}
+	sub.emitting.RLock()
+	defer sub.emitting.RUnlock()
+	for _, ch := range *sub.subscribers.Load() {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// Reporter is a metrics sink such as a StatsD client. Tags are in the
+// name:value form used by Datadog.
+type Reporter interface {
+	Count(name string, value int64, tags []string)
+	Timing(name string, d time.Duration, tags []string)
+}
+
+// ReportTo returns an Observer sending the ctxcache.hits, ctxcache.misses,
+// ctxcache.evictions counts and the ctxcache.load timings of caches to r,
+// tagged with func_id, and error for loads.
+func ReportTo(r Reporter) Observer {
+	return ObserverFunc(func(_ context.Context, ev Event) {
+		tags := []string{"func_id:" + string(ev.FuncID)}
+		switch ev.Type {
+		case EventHit:
+			r.Count("ctxcache.hits", 1, tags)
+		case EventMiss:
+			r.Count("ctxcache.misses", 1, tags)
+		case EventEvict:
+			r.Count("ctxcache.evictions", 1, tags)
+		case EventLoad:
+			r.Timing("ctxcache.load", ev.Duration, append(tags, "error:"+strconv.FormatBool(ev.Err != nil)))
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package ctxcache
+
+import (
+	"context"
+	"testing"
+)
+
+func TestEvents(t *testing.T) {
+	load := func(n int) int { return n }
+	ctx := WithCache(context.Background(), FuncID("events"), load, MaxEntries(1))
+	f, _ := FromContext(ctx, FuncID("events"), load)
+
+	subCtx, cancel := context.WithCancel(ctx)
+	events := Events(subCtx, 16)
+	f(1)
+	f(1)
+	f(2)
+	cancel()
+
+	var got []EventType
+	for ev := range events {
+		if ev.FuncID != "events" {
+			t.Fatalf("unexpected event %+v", ev)
+		}
+		got = append(got, ev.Type)
+	}
+	want := []EventType{EventMiss, EventLoad, EventHit, EventMiss, EventLoad, EventEvict}
+	if len(got) != len(want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("events = %v, want %v", got, want)
+		}
+	}
+	f(3)
+}
+
+func TestEventsDrop(t *testing.T) {
+	load := func(n int) int { return n }
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = WithCache(ctx, FuncID("events_drop"), load)
+	f, _ := FromContext(ctx, FuncID("events_drop"), load)
+	events := Events(ctx, 1)
+	for i := 0; i < 10; i++ {
+		f(i)
+	}
+	cancel()
+	n := 0
+	for range events {
+		n++
+	}
+	if n != 1 {
+		t.Fatalf("received %d events with a buffer of 1", n)
+	}
+}
+
+func TestObserve(t *testing.T) {
+	var got []EventType
+	observer := ObserverFunc(func(_ context.Context, ev Event) {
+		got = append(got, ev.Type)
+	})
+	load := func(n int) int { return n }
+	ctx := WithCache(context.Background(), FuncID("observe"), load, Observe(observer))
+	f, _ := FromContext(ctx, FuncID("observe"), load)
+	f(1)
+	f(1)
+	if len(got) != 3 || got[0] != EventMiss || got[1] != EventLoad || got[2] != EventHit {
+		t.Fatalf("observed %v", got)
+	}
+}
+
+func TestObserveReentrant(t *testing.T) {
+	var ctx context.Context
+	entries := -1
+	observer := ObserverFunc(func(_ context.Context, ev Event) {
+		if ev.Type == EventEvict {
+			s, _ := GetStats(ctx, FuncID("reentrant"))
+			entries = s.Entries
+		}
+	})
+	load := func(n int) int { return n }
+	ctx = WithCache(context.Background(), FuncID("reentrant"), load, MaxEntries(1), Observe(observer))
+	f, _ := FromContext(ctx, FuncID("reentrant"), load)
+	f(1)
+	f(2)
+	if entries != 1 {
+		t.Fatalf("entries seen on eviction = %d", entries)
+	}
+}
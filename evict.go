@@ -0,0 +1,97 @@
+package ctxcache
+
+import "context"
+
+// MaxEntries bounds the cache to n entries, evicting the least recently
+// used ones first.
+func MaxEntries(n int) Option {
+	return func(o *options) {
+		o.maxEntries = n
+	}
+}
+
+// Pin exempts keys from eviction by MaxEntries and MaxCost. Pinned entries
+// do not count towards those bounds; they still expire.
+func Pin[K comparable](keys ...K) Option {
+	set := make(map[K]bool, len(keys))
+	for _, k := range keys {
+		set[k] = true
+	}
+	return PinFunc(func(k K) bool { return set[k] })
+}
+
+// PinFunc exempts the keys for which pinned returns true from eviction, like
+// Pin.
+func PinFunc[K comparable](pinned func(K) bool) Option {
+	return func(o *options) {
+		o.pins = append(o.pins, pinned)
+	}
+}
+
+func (c *cache[K, V]) pinned(k K) bool {
+	for _, pin := range c.pins {
+		if pin(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxCost bounds the total cost of the cached entries, as reported by
+// costFn, to maxCost. The least recently used entries are evicted first and
+// values costing more than maxCost on their own are not cached.
+func MaxCost[K comparable, V any](maxCost int64, costFn func(K, V) int64) Option {
+	return func(o *options) {
+		o.maxCost = maxCost
+		o.costFn = costFn
+	}
+}
+
+// evict drops least recently used entries until the cache is within its
+// bounds, and returns their keys. c.lock must be held for writing.
+func (c *cache[K, V]) evict() []K {
+	if c.lru == nil {
+		return nil
+	}
+	var evicted []K
+	for c.overBounds() {
+		e := c.lru.Back().Value.(*entry[K, V])
+		evicted = append(evicted, e.key)
+		c.remove(e)
+	}
+	return evicted
+}
+
+// evicted counts, logs and emits the evictions of keys. It is called
+// without c.lock held, so that loggers and observers can use the cache.
+func (c *cache[K, V]) evicted(ctx context.Context, keys []K) {
+	for _, k := range keys {
+		c.countEviction()
+		c.logEvict(ctx, k)
+		c.emit(ctx, EventEvict, k, 0, nil)
+	}
+}
+
+func (c *cache[K, V]) overBounds() bool {
+	if c.opts.maxEntries > 0 && c.lru.Len() > c.opts.maxEntries {
+		return true
+	}
+	return c.costFn != nil && c.cost > c.opts.maxCost
+}
+
+// WarnAboveEntries calls fn once when the cache holds more than n entries,
+// to catch requests caching far more than intended. fn runs on the goroutine
+// of the insertion, without the cache lock held.
+func WarnAboveEntries(n int, fn func(ctx context.Context, id FuncID, entries int)) Option {
+	return func(o *options) {
+		o.warnEntries = n
+		o.warnFn = fn
+	}
+}
+
+func (c *cache[K, V]) checkGrowth(ctx context.Context, entries int) {
+	if c.opts.warnFn == nil || entries <= c.opts.warnEntries || !c.warned.CompareAndSwap(false, true) {
+		return
+	}
+	c.opts.warnFn(ctx, c.id, entries)
+}
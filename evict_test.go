@@ -0,0 +1,88 @@
+package ctxcache
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMaxEntries(t *testing.T) {
+	loads := map[int]int{}
+	id := func(n int) int {
+		loads[n]++
+		return n
+	}
+	ctx := WithCache(context.Background(), FuncID("id"), id, MaxEntries(2))
+	f, _ := FromContext(ctx, FuncID("id"), id)
+	f(1)
+	f(2)
+	f(1)
+	f(3) // evicts 2
+	f(1)
+	f(2)
+	if loads[1] != 1 || loads[2] != 2 || loads[3] != 1 {
+		t.Fatalf("loads = %v", loads)
+	}
+}
+
+func TestMaxCost(t *testing.T) {
+	loads := 0
+	makeSlice := func(n int) []byte {
+		loads++
+		return make([]byte, n)
+	}
+	cost := func(_ int, v []byte) int64 { return int64(len(v)) }
+	ctx := WithCache(context.Background(), FuncID("slice"), makeSlice, MaxCost(100, cost))
+	f, _ := FromContext(ctx, FuncID("slice"), makeSlice)
+	f(60)
+	f(30)
+	f(60)
+	if loads != 2 {
+		t.Fatalf("loads = %d, want 2", loads)
+	}
+	f(50) // evicts 30, then 60
+	f(30)
+	if loads != 4 {
+		t.Fatalf("loads = %d, want 4", loads)
+	}
+	f(200) // never cached
+	f(200)
+	if loads != 6 {
+		t.Fatalf("loads = %d, want 6", loads)
+	}
+}
+
+func TestPin(t *testing.T) {
+	loads := map[string]int{}
+	load := func(k string) string {
+		loads[k]++
+		return k
+	}
+	isTenant := func(k string) bool { return k == "tenant" }
+	ctx := WithCache(context.Background(), FuncID("pin"), load, MaxEntries(1), Pin("config"), PinFunc(isTenant))
+	f, _ := FromContext(ctx, FuncID("pin"), load)
+	for _, k := range []string{"config", "tenant", "a", "b", "config", "tenant", "a"} {
+		f(k)
+	}
+	if loads["config"] != 1 || loads["tenant"] != 1 || loads["a"] != 2 {
+		t.Fatalf("loads = %v", loads)
+	}
+}
+
+func TestWarnAboveEntries(t *testing.T) {
+	var warnings []int
+	warn := func(ctx context.Context, id FuncID, entries int) {
+		if s, _ := GetStats(ctx, id); s.Entries != entries {
+			t.Errorf("stats entries = %d, want %d", s.Entries, entries)
+		}
+		warnings = append(warnings, entries)
+	}
+	load := func(n int) int { return n }
+	ctx := WithCache(context.Background(), FuncID("grow"), load, WarnAboveEntries(2, warn))
+	f, _ := FromContext(ctx, FuncID("grow"), load)
+	for i := 0; i < 5; i++ {
+		f(i)
+	}
+	if len(warnings) != 1 || warnings[0] != 3 {
+		t.Fatalf("warnings = %v", warnings)
+	}
+}
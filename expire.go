@@ -0,0 +1,123 @@
+package ctxcache
+
+import (
+	"container/heap"
+	"context"
+	"time"
+)
+
+// TTL expires entries d after they are loaded. Expired entries load again
+// on the next call; use Janitor to reclaim their memory before that.
+func TTL(d time.Duration) Option {
+	return func(o *options) {
+		o.ttl = d
+	}
+}
+
+// AdaptiveTTL expires each entry ttl(d) after it is loaded, d being the
+// time its load took, so expensive results can be kept longer than cheap
+// ones. It overrides TTL. Entries found in a Store are given ttl(0), and a
+// non-positive ttl means the entry does not expire.
+func AdaptiveTTL(ttl func(loadTime time.Duration) time.Duration) Option {
+	return func(o *options) {
+		o.adaptiveTTL = ttl
+	}
+}
+
+// Janitor deletes expired entries every interval. The janitor stops when the
+// context the cache is installed in is done or on Release.
+func Janitor(interval time.Duration) Option {
+	return func(o *options) {
+		o.sweepInterval = interval
+	}
+}
+
+// MaxIdle expires entries that have not been read for d. Each read extends
+// the life of an entry, up to its TTL if one is set.
+func MaxIdle(d time.Duration) Option {
+	return func(o *options) {
+		o.maxIdle = d
+	}
+}
+
+func (c *cache[K, V]) ttl(loadTime time.Duration) time.Duration {
+	if c.opts.adaptiveTTL != nil {
+		return c.opts.adaptiveTTL(loadTime)
+	}
+	return c.opts.ttl
+}
+
+func (c *cache[K, V]) expired(e *entry[K, V], now int64) bool {
+	x := e.x
+	if x == nil {
+		return false
+	}
+	if x.expiry != 0 && now >= x.expiry {
+		return true
+	}
+	return c.opts.maxIdle > 0 && now-x.accessed.Load() >= int64(c.opts.maxIdle)
+}
+
+// deadline returns the earliest time e can expire at, zero if never.
+func (c *cache[K, V]) deadline(e *entry[K, V]) int64 {
+	x := e.x
+	if x == nil {
+		return 0
+	}
+	if c.opts.maxIdle <= 0 {
+		return x.expiry
+	}
+	idle := x.accessed.Load() + int64(c.opts.maxIdle)
+	if x.expiry != 0 && x.expiry < idle {
+		return x.expiry
+	}
+	return idle
+}
+
+// sweep removes the expired entries, popping them from the expiry heap so
+// the cost is proportional to the number of entries due. Entries read since
+// they were pushed are moved to their new deadline instead.
+func (c *cache[K, V]) sweep(ctx context.Context, now time.Time) {
+	var evicted []K
+	c.lock.Lock()
+	for len(c.expiries) > 0 && c.expiries[0].x.due <= now.UnixNano() {
+		e := c.expiries[0]
+		if !c.expired(e, now.UnixNano()) {
+			e.x.due = c.deadline(e)
+			heap.Fix(&c.expiries, 0)
+			continue
+		}
+		evicted = append(evicted, e.key)
+		c.remove(e)
+	}
+	c.lock.Unlock()
+	c.evicted(ctx, evicted)
+}
+
+// expiryHeap is a min-heap of entries by expiry, implementing heap.Interface.
+type expiryHeap[K comparable, V any] []*entry[K, V]
+
+func (h expiryHeap[K, V]) Len() int { return len(h) }
+
+func (h expiryHeap[K, V]) Less(i, j int) bool { return h[i].x.due < h[j].x.due }
+
+func (h expiryHeap[K, V]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].x.index = i
+	h[j].x.index = j
+}
+
+func (h *expiryHeap[K, V]) Push(x any) {
+	e := x.(*entry[K, V])
+	e.x.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *expiryHeap[K, V]) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	e.x.index = -1
+	*h = old[:len(old)-1]
+	return e
+}
@@ -0,0 +1,113 @@
+package ctxcache
+
+import (
+	"container/heap"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTTL(t *testing.T) {
+	loads := 0
+	load := func(n int) int {
+		loads++
+		return n
+	}
+	ctx := WithCache(context.Background(), FuncID("ttl"), load, TTL(10*time.Millisecond))
+	f, _ := FromContext(ctx, FuncID("ttl"), load)
+	f(1)
+	f(1)
+	time.Sleep(15 * time.Millisecond)
+	f(1)
+	if loads != 2 {
+		t.Fatalf("loads = %d, want 2", loads)
+	}
+}
+
+func TestJanitor(t *testing.T) {
+	load := func(n int) int { return n }
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctx = WithCache(ctx, FuncID("janitor"), load, TTL(time.Millisecond), Janitor(2*time.Millisecond))
+	f, _ := FromContext(ctx, FuncID("janitor"), load)
+	f(1)
+	f(2)
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		s, _ := GetStats(ctx, FuncID("janitor"))
+		if s.Entries == 0 && s.Evictions == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expired entries not swept: %+v", s)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestExpiryHeap(t *testing.T) {
+	data := lockedMap[int, int]{}
+	process := &counters{}
+	c := &cache[int, int]{data: data, opts: &options{}, process: process, live: newLiveEntries(process)}
+	for i, expiry := range []int64{5, 1, 4, 2, 3} {
+		e := &entry[int, int]{key: i, x: &entryExtra[int, int]{expiry: expiry, due: expiry, index: -1}}
+		data[i] = e
+		heap.Push(&c.expiries, e)
+	}
+	c.remove(data[2]) // expiry 4
+	c.sweep(context.Background(), time.Unix(0, 4))
+	if len(data) != 1 || data[0] == nil || len(c.expiries) != 1 {
+		t.Fatalf("left %d entries, %d in heap", len(data), len(c.expiries))
+	}
+}
+
+func TestMaxIdle(t *testing.T) {
+	loads := 0
+	load := func(n int) int {
+		loads++
+		return n
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctx = WithCache(ctx, FuncID("idle"), load, MaxIdle(20*time.Millisecond), Janitor(time.Millisecond))
+	f, _ := FromContext(ctx, FuncID("idle"), load)
+	f(1)
+	for i := 0; i < 5; i++ {
+		time.Sleep(10 * time.Millisecond)
+		f(1)
+	}
+	if loads != 1 {
+		t.Fatalf("loads = %d: reads did not extend the entry", loads)
+	}
+	time.Sleep(40 * time.Millisecond)
+	if s, _ := GetStats(ctx, FuncID("idle")); s.Entries != 0 {
+		t.Fatalf("idle entry not swept: %+v", s)
+	}
+}
+
+func TestAdaptiveTTL(t *testing.T) {
+	load := func(n int) int {
+		time.Sleep(time.Duration(n) * time.Millisecond)
+		return n
+	}
+	ttl := func(d time.Duration) time.Duration {
+		if d >= 5*time.Millisecond {
+			return time.Hour
+		}
+		return time.Minute
+	}
+	ctx := WithCache(context.Background(), FuncID("adaptive"), load, AdaptiveTTL(ttl))
+	f, _ := FromContext(ctx, FuncID("adaptive"), load)
+	f(0)
+	f(5)
+	for k, m := range Metadata[int, int](ctx, FuncID("adaptive")) {
+		want := time.Minute
+		if k == 5 {
+			want = time.Hour
+		}
+		if got := m.Expires.Sub(m.Created); got != want {
+			t.Errorf("key %d expires after %s, want %s", k, got, want)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package ctxcache
+
+import (
+	"expvar"
+	"sync"
+)
+
+var expvarLock sync.Mutex
+
+// PublishExpvar publishes ProcessStats in expvar under name, so the counters
+// and entry counts of every FuncID show up in /debug/vars. Publishing the
+// same name again is a no-op.
+func PublishExpvar(name string) {
+	expvarLock.Lock()
+	defer expvarLock.Unlock()
+	if expvar.Get(name) != nil {
+		return
+	}
+	expvar.Publish(name, expvar.Func(func() any {
+		return ProcessStats()
+	}))
+}
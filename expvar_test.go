@@ -0,0 +1,32 @@
+package ctxcache
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"testing"
+)
+
+func TestPublishExpvar(t *testing.T) {
+	PublishExpvar("ctxcache_test")
+	PublishExpvar("ctxcache_test")
+
+	before := ProcessStats()[FuncID("expvar_double")]
+	double := func(n int) int { return n * 2 }
+	ctx := WithCache(context.Background(), FuncID("expvar_double"), double)
+	f, _ := FromContext(ctx, FuncID("expvar_double"), double)
+	f(1)
+	f(2)
+
+	var stats map[FuncID]Stats
+	if err := json.Unmarshal([]byte(expvar.Get("ctxcache_test").String()), &stats); err != nil {
+		t.Fatal(err)
+	}
+	if s := stats["expvar_double"]; s.Loads-before.Loads != 2 || s.Entries-before.Entries != 2 {
+		t.Fatalf("stats = %+v", s)
+	}
+	Release(ctx)
+	if s := ProcessStats()["expvar_double"]; s.Entries != before.Entries {
+		t.Fatalf("entries after Release = %d, want %d", s.Entries, before.Entries)
+	}
+}
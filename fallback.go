@@ -0,0 +1,77 @@
+package ctxcache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Fallback makes the cache return f(key) instead of an error when the
+// loader fails or the MaxLoads quota is used up. Fallback values are not
+// cached.
+func Fallback[K comparable, V any](f func(K) V) Option {
+	return func(o *options) {
+		o.fallback = f
+	}
+}
+
+func (c *cache[K, V]) fallback(k K, v V, err error) (V, error) {
+	if c.fallbackFn == nil {
+		return v, err
+	}
+	return c.fallbackFn(k), nil
+}
+
+// CacheErrors caches the errors of failed loads matching any of match, so
+// they are returned again until the entry expires instead of calling the
+// loader again. Errors are not cached by default.
+//
+//	ctxcache.CacheErrors(ctxcache.ErrorIs(ErrNotFound))
+func CacheErrors(match ...func(error) bool) Option {
+	return func(o *options) {
+		o.cacheErrs = append(o.cacheErrs, match...)
+	}
+}
+
+// ErrorIs returns a matcher for CacheErrors of the errors that are target
+// according to errors.Is.
+func ErrorIs(target error) func(error) bool {
+	return func(err error) bool {
+		return errors.Is(err, target)
+	}
+}
+
+// ErrorAs returns a matcher for CacheErrors of the errors that have an E in
+// their chain according to errors.As.
+func ErrorAs[E error]() func(error) bool {
+	return func(err error) bool {
+		var target E
+		return errors.As(err, &target)
+	}
+}
+
+// FailureCooldown caches the errors of the loader for d, so a failing key
+// returns its error without calling the loader again until d has passed.
+// With CacheErrors, only the errors it matches are cached, for d. Context
+// cancellations and deadlines are never cached, as they come from a single
+// caller.
+func FailureCooldown(d time.Duration) Option {
+	return func(o *options) {
+		o.cooldown = d
+	}
+}
+
+func (c *cache[K, V]) cacheable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if len(c.opts.cacheErrs) == 0 {
+		return c.opts.cooldown > 0
+	}
+	for _, match := range c.opts.cacheErrs {
+		if match(err) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package ctxcache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFallback(t *testing.T) {
+	loads := 0
+	load := func(_ context.Context, n int) (string, error) {
+		loads++
+		if n < 0 {
+			return "", errors.New("negative")
+		}
+		return "ok", nil
+	}
+	unknown := func(int) string { return "unknown" }
+	ctx := WithCacheE(context.Background(), FuncID("name"), load, Fallback(unknown), MaxLoads(3))
+	f, _ := FromContextE(ctx, FuncID("name"), load)
+
+	if v, err := f(-1); err != nil || v != "unknown" {
+		t.Fatalf("f(-1) = %q, %v", v, err)
+	}
+	if v, _ := f(-1); v != "unknown" || loads != 2 {
+		t.Fatalf("fallback value cached: loads = %d", loads)
+	}
+	f(1)
+	if v, err := f(2); err != nil || v != "unknown" {
+		t.Fatalf("f(2) over quota = %q, %v", v, err)
+	}
+}
+
+type statusError struct{ code int }
+
+func (e *statusError) Error() string { return "status error" }
+
+func TestCacheErrors(t *testing.T) {
+	errNotFound := errors.New("not found")
+	loads := 0
+	load := func(_ context.Context, n int) (int, error) {
+		loads++
+		switch n {
+		case 1:
+			return 0, errNotFound
+		case 2:
+			return 0, &statusError{code: 404}
+		case 3:
+			return 0, context.DeadlineExceeded
+		}
+		return n, nil
+	}
+	ctx := WithCacheE(context.Background(), FuncID("errors"), load,
+		CacheErrors(ErrorIs(errNotFound), ErrorAs[*statusError]()))
+	f, _ := FromContextE(ctx, FuncID("errors"), load)
+	for i := 0; i < 2; i++ {
+		if _, err := f(1); !errors.Is(err, errNotFound) {
+			t.Fatalf("f(1) err = %v", err)
+		}
+		if _, err := f(2); err == nil {
+			t.Fatal("f(2) err = nil")
+		}
+		if _, err := f(3); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("f(3) err = %v", err)
+		}
+	}
+	if loads != 4 {
+		t.Fatalf("loads = %d, want 4", loads)
+	}
+	for k := range Entries[int, int](ctx, FuncID("errors")) {
+		t.Fatalf("cached error listed as entry %d", k)
+	}
+}
+
+func TestFailureCooldown(t *testing.T) {
+	loads := 0
+	load := func(_ context.Context, n int) (int, error) {
+		loads++
+		return 0, errors.New("down")
+	}
+	ctx := WithCacheE(context.Background(), FuncID("cooldown"), load, FailureCooldown(20*time.Millisecond), TTL(time.Hour))
+	f, _ := FromContextE(ctx, FuncID("cooldown"), load)
+	for i := 0; i < 3; i++ {
+		if _, err := f(1); err == nil {
+			t.Fatal("error not returned")
+		}
+	}
+	if loads != 1 {
+		t.Fatalf("loads = %d during cooldown", loads)
+	}
+	time.Sleep(30 * time.Millisecond)
+	f(1)
+	if loads != 2 {
+		t.Fatalf("loads = %d after cooldown", loads)
+	}
+}
+
+func TestFailureCooldownMatchers(t *testing.T) {
+	errNotFound := errors.New("not found")
+	loads := 0
+	load := func(ctx context.Context, n int) (int, error) {
+		loads++
+		switch {
+		case ctx.Err() != nil:
+			return 0, ctx.Err()
+		case n == 0:
+			return 0, errNotFound
+		}
+		return 0, errors.New("down")
+	}
+	ctx := WithCacheE(context.Background(), FuncID("cooldown"), load, FailureCooldown(time.Hour), CacheErrors(ErrorIs(errNotFound)))
+	f, _ := FromContextE(ctx, FuncID("cooldown"), load)
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	g, _ := FromContextE(canceled, FuncID("cooldown"), load)
+	g(0)
+	f(0)
+	f(0)
+	f(1)
+	f(1)
+	if loads != 4 {
+		t.Fatalf("loads = %d, want only the not found error cached", loads)
+	}
+}
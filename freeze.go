@@ -0,0 +1,70 @@
+package ctxcache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrFrozen can be passed to Freeze to make misses of a frozen cache fail.
+var ErrFrozen = errors.New("ctxcache: cache is frozen")
+
+// Freeze turns the cache installed in ctx under ctxKey into an immutable
+// snapshot of its live entries, read without locking, for read-only phases
+// after a warmup. Later misses return missErr, or call the loader without
+// caching its result if missErr is nil, still through MaxLoads, RateLimit
+// and the loader wrappers such as Replay. The background workers of the
+// cache stop, and invalidation no longer affects the snapshot.
+func Freeze(ctx context.Context, ctxKey FuncID, missErr error) bool {
+	c, ok := cacheValue(ctx, ctxKey).(anyCache)
+	if !ok {
+		return false
+	}
+	c.stopWorkers()
+	c.freeze(missErr)
+	return true
+}
+
+// frozen is the snapshot read by a frozen cache.
+type frozen[K comparable, V any] struct {
+	values  map[K]V
+	missErr error
+}
+
+func (c *cache[K, V]) freeze(missErr error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	fz := &frozen[K, V]{values: make(map[K]V, c.data.len()), missErr: missErr}
+	now := time.Now().UnixNano()
+	for k, e := range c.data.all() {
+		if v, alive := e.get(); alive && !c.expired(e, now) && e.err() == nil {
+			fz.values[k] = v
+		}
+	}
+	c.frozen.Store(fz)
+}
+
+func (c *cache[K, V]) frozenGet(ctx context.Context, k K, fz *frozen[K, V]) (V, error) {
+	if v, ok := fz.values[k]; ok {
+		c.countHit()
+		return v, nil
+	}
+	c.countMiss()
+	c.logMiss(ctx, k)
+	c.emit(ctx, EventMiss, k, 0, nil)
+	if fz.missErr != nil {
+		var zero V
+		return c.fallback(k, zero, fz.missErr)
+	}
+	// Misses load as usual, through the quotas and wrappers, but their
+	// result is not cached.
+	if err := c.reserveLoad(ctx); err != nil {
+		var zero V
+		return c.fallback(k, zero, err)
+	}
+	v, _, err := c.call(ctx, k)
+	if err != nil {
+		return c.fallback(k, v, err)
+	}
+	return v, nil
+}
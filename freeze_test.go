@@ -0,0 +1,59 @@
+package ctxcache
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestFreeze(t *testing.T) {
+	calls := 0
+	load := func(n int) int {
+		calls++
+		return n * 2
+	}
+	for _, missErr := range []error{nil, ErrFrozen} {
+		calls = 0
+		ctx := WithCache(context.Background(), FuncID("freeze"), load)
+		get, _ := FromContextE(ctx, FuncID("freeze"), plainLoader[int, int](load))
+		get(1)
+		if !Freeze(ctx, FuncID("freeze"), missErr) {
+			t.Fatal("cache not frozen")
+		}
+		if v, err := get(1); v != 2 || err != nil || calls != 1 {
+			t.Fatalf("frozen hit = %d, %v after %d calls", v, err, calls)
+		}
+		v, err := get(2)
+		if missErr != nil {
+			if !errors.Is(err, ErrFrozen) || calls != 1 {
+				t.Fatalf("frozen miss = %d, %v after %d calls", v, err, calls)
+			}
+			continue
+		}
+		get(2)
+		if v != 4 || err != nil || calls != 3 {
+			t.Fatalf("frozen miss = %d, %v after %d calls", v, err, calls)
+		}
+	}
+	if Freeze(context.Background(), FuncID("freeze"), nil) {
+		t.Fatal("froze a missing cache")
+	}
+}
+
+func TestFreezeMissQuota(t *testing.T) {
+	calls := 0
+	load := func(n int) int {
+		calls++
+		return n
+	}
+	ctx := WithCache(context.Background(), FuncID("freeze"), load, MaxLoads(1))
+	get, _ := FromContextE(ctx, FuncID("freeze"), plainLoader[int, int](load))
+	Freeze(ctx, FuncID("freeze"), nil)
+	get(1)
+	if _, err := get(2); !errors.Is(err, ErrMaxLoads) || calls != 1 {
+		t.Fatalf("frozen miss over quota = %v after %d calls", err, calls)
+	}
+	if s, _ := GetStats(ctx, FuncID("freeze")); s.Loads != 1 || s.Misses != 2 {
+		t.Fatalf("stats = %+v", s)
+	}
+}
@@ -0,0 +1,50 @@
+package ctxcache
+
+import "context"
+
+// Future is the pending result of LoadAsync.
+type Future[V any] struct {
+	done chan struct{}
+	v    V
+	err  error
+}
+
+// LoadAsync starts loading k through the cache installed in ctx under
+// ctxKey, or through f if there is none, and returns at once. Concurrent
+// loads of k through the cache are shared as with StrictOnce. Callers can
+// start several loads and join them later:
+//
+//	user := ctxcache.LoadAsync(ctx, "user", loadUser, userID)
+//	orders := ctxcache.LoadAsync(ctx, "orders", loadOrders, userID)
+//	u, err := user.Wait(ctx)
+func LoadAsync[K comparable, V any](ctx context.Context, ctxKey FuncID, f CacheFuncE[K, V], k K) *Future[V] {
+	fut := &Future[V]{done: make(chan struct{})}
+	load := f
+	if c, ok := cacheValue(ctx, ctxKey).(*cache[K, V]); ok {
+		load = func(ctx context.Context, k K) (V, error) {
+			return c.fetch(ctx, scopeOf(ctx), k, true)
+		}
+	}
+	go func() {
+		defer close(fut.done)
+		fut.v, fut.err = load(ctx, k)
+	}()
+	return fut
+}
+
+// Done returns a channel closed once the load completes.
+func (f *Future[V]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait waits for the load to complete and returns its result, or returns
+// the error of ctx if it is done first. The load keeps running then.
+func (f *Future[V]) Wait(ctx context.Context) (V, error) {
+	select {
+	case <-f.done:
+		return f.v, f.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
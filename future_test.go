@@ -0,0 +1,43 @@
+package ctxcache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoadAsync(t *testing.T) {
+	var calls atomic.Int64
+	load := func(_ context.Context, n int) (int, error) {
+		calls.Add(1)
+		time.Sleep(5 * time.Millisecond)
+		return n * 2, nil
+	}
+	ctx := WithCacheE(context.Background(), FuncID("async"), load)
+	futures := []*Future[int]{
+		LoadAsync(ctx, FuncID("async"), load, 1),
+		LoadAsync(ctx, FuncID("async"), load, 1),
+		LoadAsync(ctx, FuncID("async"), load, 2),
+	}
+	for i, want := range []int{2, 2, 4} {
+		if v, err := futures[i].Wait(ctx); v != want || err != nil {
+			t.Fatalf("future %d = %d, %v", i, v, err)
+		}
+	}
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("loader called %d times, want 2", n)
+	}
+
+	fut := LoadAsync(context.Background(), FuncID("async"), load, 3)
+	if v, err := fut.Wait(context.Background()); v != 6 || err != nil {
+		t.Fatalf("uncached future = %d, %v", v, err)
+	}
+
+	waitCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := LoadAsync(ctx, FuncID("async"), load, 4).Wait(waitCtx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Wait err = %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package ctxcache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Generational is an in-process Store keeping entries in two generations,
+// an approximate LRU without per-hit bookkeeping: hits in the new
+// generation only take a read lock. When the new generation is full, or
+// every interval if positive, the old generation is dropped and the new one
+// becomes old. Hits in the old generation move the entry back to the new
+// one. It is safe to share between goroutines and requests.
+type Generational[K comparable, V any] struct {
+	lock     sync.RWMutex
+	young    map[K]V
+	old      map[K]V
+	size     int
+	interval time.Duration
+	rotated  time.Time
+	gen      atomic.Uint64
+	now      func() time.Time
+}
+
+// NewGenerational returns a Generational holding up to size entries per
+// generation, so between size and 2*size entries once warm, rotating
+// every interval too if interval is positive.
+func NewGenerational[K comparable, V any](size int, interval time.Duration) *Generational[K, V] {
+	g := &Generational[K, V]{
+		young:    make(map[K]V),
+		size:     size,
+		interval: interval,
+		now:      time.Now,
+	}
+	g.rotated = g.now()
+	return g
+}
+
+func (g *Generational[K, V]) Get(_ context.Context, key K) (V, bool, error) {
+	g.lock.RLock()
+	v, ok := g.young[key]
+	due := g.due()
+	g.lock.RUnlock()
+	if ok && !due {
+		return v, true, nil
+	}
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if g.due() {
+		g.rotate()
+	}
+	if v, ok := g.young[key]; ok {
+		return v, true, nil
+	}
+	v, ok = g.old[key]
+	if ok {
+		delete(g.old, key)
+		g.set(key, v)
+	}
+	return v, ok, nil
+}
+
+func (g *Generational[K, V]) Set(_ context.Context, key K, value V) error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.set(key, value)
+	return nil
+}
+
+// Delete invalidates key. Loads that started before the call will not
+// write their result back.
+func (g *Generational[K, V]) Delete(_ context.Context, key K) error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.gen.Add(1)
+	delete(g.young, key)
+	delete(g.old, key)
+	return nil
+}
+
+// Purge invalidates all entries.
+func (g *Generational[K, V]) Purge() {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.gen.Add(1)
+	g.young, g.old = make(map[K]V), nil
+}
+
+// Len returns the number of entries in g.
+func (g *Generational[K, V]) Len() int {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+	return len(g.young) + len(g.old)
+}
+
+// due reports whether the interval rotation is due. g.lock must be held.
+func (g *Generational[K, V]) due() bool {
+	return g.interval > 0 && g.now().Sub(g.rotated) >= g.interval
+}
+
+// rotate drops the old generation. g.lock must be held for writing.
+func (g *Generational[K, V]) rotate() {
+	g.old, g.young = g.young, make(map[K]V, len(g.young))
+	g.rotated = g.now()
+}
+
+// set stores value in the young generation. g.lock must be held for
+// writing.
+func (g *Generational[K, V]) set(key K, value V) {
+	if _, ok := g.young[key]; g.due() || !ok && g.size > 0 && len(g.young) >= g.size {
+		g.rotate()
+	}
+	delete(g.old, key)
+	g.young[key] = value
+}
+
+func (g *Generational[K, V]) token() uint64 {
+	return g.gen.Load()
+}
+
+func (g *Generational[K, V]) setIfToken(_ context.Context, key K, value V, token uint64, _ time.Duration) error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if g.gen.Load() == token {
+		g.set(key, value)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package ctxcache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestGenerational(t *testing.T) {
+	ctx := context.Background()
+	g := NewGenerational[int, int](2, 0)
+	g.Set(ctx, 1, 1)
+	g.Set(ctx, 2, 2)
+	g.Set(ctx, 3, 3) // rotates: {3} young, {1, 2} old
+	if _, ok, _ := g.Get(ctx, 1); !ok {
+		t.Fatal("old generation entry missed")
+	}
+	g.Set(ctx, 4, 4) // rotates: {4} young, {3, 1} old
+	for k, want := range map[int]bool{1: true, 2: false, 3: true, 4: true} {
+		if _, ok, _ := g.Get(ctx, k); ok != want {
+			t.Fatalf("Get(%d) ok = %v, want %v", k, ok, want)
+		}
+	}
+
+	now := time.Now()
+	g = NewGenerational[int, int](0, time.Minute)
+	g.now = func() time.Time { return now }
+	g.rotated = now
+	g.Set(ctx, 1, 1)
+	now = now.Add(time.Minute)
+	g.Set(ctx, 2, 2)
+	now = now.Add(time.Minute)
+	if _, ok, _ := g.Get(ctx, 2); !ok {
+		t.Fatal("entry dropped after one rotation")
+	}
+	now = now.Add(2 * time.Minute)
+	g.Get(ctx, 2)
+	if _, ok, _ := g.Get(ctx, 1); ok || g.Len() != 1 {
+		t.Fatalf("Len = %d after two rotations", g.Len())
+	}
+
+	token := g.token()
+	g.Delete(ctx, 2)
+	g.setIfToken(ctx, 2, 2, token, 0)
+	if g.Len() != 0 {
+		t.Fatal("stale load written after invalidation")
+	}
+}
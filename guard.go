@@ -0,0 +1,26 @@
+package ctxcache
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// GuardMutations keeps a deep copy of every cached value and panics on a hit
+// if the value no longer equals it, that is if a caller mutated what the
+// cache returned. It doubles the memory held and slows every hit, so it is
+// meant for debug builds and tests.
+func GuardMutations() Option {
+	return func(o *options) {
+		o.guard = true
+	}
+}
+
+func (c *cache[K, V]) verify(e *entry[K, V]) {
+	if !c.opts.guard {
+		return
+	}
+	if v, _ := e.get(); !reflect.DeepEqual(v, e.x.snapshot) {
+		panic(fmt.Sprintf("ctxcache: cached value of %s for key %s was mutated: got %#v, cached %#v",
+			c.id, c.keyString(e.key), v, e.x.snapshot))
+	}
+}
@@ -0,0 +1,22 @@
+package ctxcache
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGuardMutations(t *testing.T) {
+	load := func(n int) []int { return []int{n} }
+	ctx := WithCache(context.Background(), FuncID("guard"), load, GuardMutations())
+	f, _ := FromContext(ctx, FuncID("guard"), load)
+
+	f(1)[0] = 1
+	f(1)
+	f(2)[0] = 3
+	defer func() {
+		if recover() == nil {
+			t.Fatal("mutation not detected")
+		}
+	}()
+	f(2)
+}
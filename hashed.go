@@ -0,0 +1,86 @@
+package ctxcache
+
+import (
+	"context"
+	"fmt"
+	"hash/maphash"
+	"reflect"
+	"sync"
+)
+
+// Hasher hashes keys that are not comparable, such as slices, maps or
+// structs holding them.
+type Hasher[K any] func(K) uint64
+
+var hashSeed = maphash.MakeSeed()
+
+// FormatHasher returns a Hasher hashing the %#v representation of keys.
+func FormatHasher[K any]() Hasher[K] {
+	return func(k K) uint64 {
+		return maphash.String(hashSeed, fmt.Sprintf("%#v", k))
+	}
+}
+
+// bucket holds the entries whose keys share a hash.
+type bucket[K any, V any] struct {
+	lock    sync.Mutex
+	entries []hashedEntry[K, V]
+}
+
+type hashedEntry[K any, V any] struct {
+	key   K
+	value V
+}
+
+func newBucket[K any, V any](uint64) *bucket[K, V] {
+	return &bucket[K, V]{}
+}
+
+// WithHashedCache is WithCache for keys that are not comparable. Keys are
+// stored by hash, and keys colliding on a hash are told apart with
+// reflect.DeepEqual.
+func WithHashedCache[K any, V any](ctx context.Context, ctxKey FuncID, f func(K) V, hash Hasher[K], opts ...Option) context.Context {
+	return WithComparatorCache(ctx, ctxKey, f, hash, func(a, b K) bool { return reflect.DeepEqual(a, b) }, opts...)
+}
+
+// WithComparatorCache is WithHashedCache with custom key equality, such as
+// proto.Equal for protobuf messages or strings.EqualFold for
+// case-insensitive strings. Keys that are equal must have the same hash.
+// Retrieve the cache with FromContextHashed.
+func WithComparatorCache[K any, V any](ctx context.Context, ctxKey FuncID, f func(K) V, hash Hasher[K], equal func(a, b K) bool, opts ...Option) context.Context {
+	ctx = WithCache(ctx, ctxKey, newBucket[K, V], opts...)
+	return context.WithValue(ctx, hashedKey{ctxKey}, &hashedCache[K, V]{loader: f, hash: hash, equal: equal})
+}
+
+type hashedKey struct{ id FuncID }
+
+type hashedCache[K any, V any] struct {
+	loader func(K) V
+	hash   Hasher[K]
+	equal  func(a, b K) bool
+}
+
+// FromContextHashed is FromContext for caches installed by WithHashedCache.
+func FromContextHashed[K any, V any](ctx context.Context, ctxKey FuncID, f func(K) V) (func(K) V, bool) {
+	hc, ok := ctx.Value(hashedKey{ctxKey}).(*hashedCache[K, V])
+	if !ok {
+		return f, false
+	}
+	get, ok := FromContext(ctx, ctxKey, newBucket[K, V])
+	if !ok {
+		return f, false
+	}
+	return func(k K) V {
+		b := get(hc.hash(k))
+		b.lock.Lock()
+		defer b.lock.Unlock()
+		for _, e := range b.entries {
+			if hc.equal(e.key, k) {
+				return e.value
+			}
+		}
+		v := hc.loader(k)
+		b.entries = append(b.entries, hashedEntry[K, V]{key: k, value: v})
+		return v
+	}, true
+}
@@ -0,0 +1,54 @@
+package ctxcache
+
+import (
+	"context"
+	"hash/maphash"
+	"strings"
+	"testing"
+)
+
+func TestHashedCache(t *testing.T) {
+	loads := 0
+	sum := func(ns []int) int {
+		loads++
+		s := 0
+		for _, n := range ns {
+			s += n
+		}
+		return s
+	}
+	// a constant hash forces every key into the same bucket
+	collide := func([]int) uint64 { return 0 }
+
+	for _, hash := range []Hasher[[]int]{FormatHasher[[]int](), collide} {
+		loads = 0
+		ctx := WithHashedCache(context.Background(), FuncID("sum"), sum, hash)
+		f, ok := FromContextHashed(ctx, FuncID("sum"), sum)
+		if !ok {
+			t.Fatal("cache not installed")
+		}
+		if f([]int{1, 2}) != 3 || f([]int{1, 2}) != 3 || f([]int{2, 2}) != 4 {
+			t.Fatal("wrong sum")
+		}
+		if loads != 2 {
+			t.Fatalf("loads = %d, want 2", loads)
+		}
+	}
+}
+
+func TestComparatorCache(t *testing.T) {
+	loads := 0
+	greet := func(name string) string {
+		loads++
+		return "hello " + strings.ToLower(name)
+	}
+	hash := func(s string) uint64 { return maphash.String(hashSeed, strings.ToLower(s)) }
+	ctx := WithComparatorCache(context.Background(), FuncID("greet"), greet, hash, strings.EqualFold)
+	f, _ := FromContextHashed(ctx, FuncID("greet"), greet)
+	for _, name := range []string{"Ada", "ADA", "ada", "Bob"} {
+		f(name)
+	}
+	if loads != 2 {
+		t.Fatalf("loads = %d, want 2 for case-insensitive keys", loads)
+	}
+}
@@ -0,0 +1,47 @@
+package ctxcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// minHealthLoads is the number of loads before MaxErrorRate applies.
+const minHealthLoads = 10
+
+// MaxErrorRate makes Healthy report the cache when more than rate of its
+// loads since install failed, once it made at least 10 loads.
+func MaxErrorRate(rate float64) Option {
+	return func(o *options) {
+		o.maxErrorRate = rate
+	}
+}
+
+// Healthy returns nil if every cache installed in ctx, and each of its
+// Namespace partitions, is healthy, and otherwise an error describing each
+// problem: an error rate above MaxErrorRate, or a Store whose last call
+// failed. It suits the readiness probes of services depending on the shared
+// tier.
+func Healthy(ctx context.Context) error {
+	var errs []error
+	allCaches(ctx, withPartitions(func(c anyCache) {
+		if err := c.health(); err != nil {
+			errs = append(errs, err)
+		}
+	}))
+	return errors.Join(errs...)
+}
+
+func (c *cache[K, V]) health() error {
+	var errs []error
+	if s := c.counters.snapshot(); c.opts.maxErrorRate > 0 && s.Loads >= minHealthLoads {
+		rate := float64(s.Errors) / float64(s.Loads)
+		if rate > c.opts.maxErrorRate {
+			errs = append(errs, fmt.Errorf("ctxcache: %s: %.1f%% of loads failed, above %.1f%%", c.name(), rate*100, c.opts.maxErrorRate*100))
+		}
+	}
+	if err := c.storeErr.Load(); err != nil && *err != nil {
+		errs = append(errs, fmt.Errorf("ctxcache: %s: store unreachable: %w", c.name(), *err))
+	}
+	return errors.Join(errs...)
+}
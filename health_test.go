@@ -0,0 +1,54 @@
+package ctxcache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// downStore is a Store whose backend is unreachable.
+type downStore struct{}
+
+var errDown = errors.New("connection refused")
+
+func (downStore) Get(context.Context, int) (int, bool, error) { return 0, false, errDown }
+func (downStore) Set(context.Context, int, int) error         { return errDown }
+func (downStore) Delete(context.Context, int) error           { return errDown }
+
+func TestHealthy(t *testing.T) {
+	errOdd := errors.New("odd")
+	half := func(_ context.Context, n int) (int, error) {
+		if n%2 != 0 {
+			return 0, errOdd
+		}
+		return n / 2, nil
+	}
+	ctx := WithCacheE(context.Background(), FuncID("half"), half, MaxErrorRate(0.25))
+	f, _ := FromContextE(ctx, FuncID("half"), half)
+	for n := range 8 {
+		f(n * 2)
+	}
+	if err := Healthy(ctx); err != nil {
+		t.Fatalf("Healthy = %v", err)
+	}
+	for n := range 4 {
+		f(n*2 + 1)
+	}
+	if err := Healthy(ctx); err == nil || !strings.Contains(err.Error(), "33.3% of loads failed") {
+		t.Fatalf("Healthy = %v", err)
+	}
+	if s, _ := GetStats(ctx, FuncID("half")); s.Errors != 4 {
+		t.Fatalf("Errors = %d", s.Errors)
+	}
+
+	inc := func(n int) int { return n + 1 }
+	ctx = WithCache(context.Background(), FuncID("inc"), inc, WithStore[int, int](downStore{}))
+	g, _ := FromContext(ctx, FuncID("inc"), inc)
+	if g(1) != 2 {
+		t.Fatal("unexpected value")
+	}
+	if err := Healthy(ctx); !errors.Is(err, errDown) {
+		t.Fatalf("Healthy = %v", err)
+	}
+}
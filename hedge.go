@@ -0,0 +1,55 @@
+package ctxcache
+
+import (
+	"context"
+	"time"
+)
+
+// Hedge starts a second loader call when the first has not returned within
+// delay, and keeps the first successful result, reducing the tail latency
+// of flaky backends. The call left behind sees its context canceled.
+func Hedge(delay time.Duration) Option {
+	return func(o *options) {
+		o.hedgeDelay = delay
+	}
+}
+
+func hedged[K comparable, V any](load CacheFuncE[K, V], delay time.Duration) CacheFuncE[K, V] {
+	type result struct {
+		v   V
+		err error
+	}
+	return func(ctx context.Context, k K) (V, error) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		results := make(chan result, 2)
+		call := func() {
+			v, err := load(ctx, k)
+			results <- result{v, err}
+		}
+		go call()
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		hedge := timer.C
+		var err error
+		for pending := 1; ; {
+			select {
+			case <-hedge:
+				hedge = nil
+				pending++
+				go call()
+			case r := <-results:
+				pending--
+				if r.err == nil {
+					return r.v, nil
+				}
+				if err == nil {
+					err = r.err
+				}
+				if pending == 0 {
+					return r.v, err
+				}
+			}
+		}
+	}
+}
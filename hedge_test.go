@@ -0,0 +1,54 @@
+package ctxcache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHedge(t *testing.T) {
+	var calls atomic.Int64
+	canceled := make(chan struct{})
+	load := func(ctx context.Context, n int) (int, error) {
+		if calls.Add(1) == 1 {
+			<-ctx.Done()
+			close(canceled)
+			return 0, ctx.Err()
+		}
+		return n, nil
+	}
+	ctx := WithCacheE(context.Background(), FuncID("hedge"), load, Hedge(time.Millisecond))
+	f, _ := FromContextE(ctx, FuncID("hedge"), load)
+	if v, err := f(7); v != 7 || err != nil {
+		t.Fatalf("f(7) = %d, %v", v, err)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("slow call not canceled")
+	}
+	if s, _ := GetStats(ctx, FuncID("hedge")); s.Loads != 1 || calls.Load() != 2 {
+		t.Fatalf("stats = %+v, calls = %d", s, calls.Load())
+	}
+}
+
+func TestHedgeAfterFailure(t *testing.T) {
+	var calls atomic.Int64
+	failed := make(chan struct{})
+	load := func(ctx context.Context, n int) (int, error) {
+		if calls.Add(1) == 1 {
+			<-failed
+			return 0, errors.New("first failed")
+		}
+		close(failed)
+		time.Sleep(10 * time.Millisecond) // let the first call fail first
+		return n, nil
+	}
+	ctx := WithCacheE(context.Background(), FuncID("hedge"), load, Hedge(time.Millisecond))
+	f, _ := FromContextE(ctx, FuncID("hedge"), load)
+	if v, err := f(7); v != 7 || err != nil {
+		t.Fatalf("f(7) = %d, %v", v, err)
+	}
+}
@@ -0,0 +1,80 @@
+package ctxcache
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+)
+
+// TrackHotKeys counts the hits of the n hottest keys of the cache with the
+// space-saving algorithm, in memory bounded by n, for HotKeys.
+func TrackHotKeys(n int) Option {
+	return func(o *options) {
+		o.hotKeys = n
+	}
+}
+
+// KeyCount is the estimated hit count of a key. Count may overestimate the
+// hits by up to Error. Key is formatted like in logs.
+type KeyCount struct {
+	Key   string `json:"key"`
+	Count uint64 `json:"count"`
+	Error uint64 `json:"error"`
+}
+
+// HotKeys returns the hottest keys of the cache installed in ctx under
+// ctxKey with TrackHotKeys, hottest first.
+func HotKeys(ctx context.Context, ctxKey FuncID) []KeyCount {
+	c, ok := cacheValue(ctx, ctxKey).(anyCache)
+	if !ok {
+		return nil
+	}
+	return c.hotKeys()
+}
+
+// topK implements the space-saving algorithm over at most n keys.
+type topK[K comparable] struct {
+	lock   sync.Mutex
+	n      int
+	counts map[K]*KeyCount
+}
+
+func (t *topK[K]) add(k K) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if kc, ok := t.counts[k]; ok {
+		kc.Count++
+		return
+	}
+	if len(t.counts) < t.n {
+		t.counts[k] = &KeyCount{Count: 1}
+		return
+	}
+	var minKey K
+	var min *KeyCount
+	for key, kc := range t.counts {
+		if min == nil || kc.Count < min.Count {
+			minKey, min = key, kc
+		}
+	}
+	delete(t.counts, minKey)
+	t.counts[k] = &KeyCount{Count: min.Count + 1, Error: min.Count}
+}
+
+func (c *cache[K, V]) hotKeys() []KeyCount {
+	t := c.hot
+	if t == nil {
+		return nil
+	}
+	t.lock.Lock()
+	keys := make([]KeyCount, 0, len(t.counts))
+	for k, kc := range t.counts {
+		keys = append(keys, KeyCount{Key: c.keyString(k), Count: kc.Count, Error: kc.Error})
+	}
+	t.lock.Unlock()
+	slices.SortFunc(keys, func(a, b KeyCount) int {
+		return cmp.Compare(b.Count, a.Count)
+	})
+	return keys
+}
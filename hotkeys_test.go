@@ -0,0 +1,29 @@
+package ctxcache
+
+import (
+	"context"
+	"testing"
+)
+
+func TestHotKeys(t *testing.T) {
+	load := func(n int) int { return n }
+	ctx := WithCache(context.Background(), FuncID("hot"), load, TrackHotKeys(2))
+	f, _ := FromContext(ctx, FuncID("hot"), load)
+	for i := 0; i < 10; i++ {
+		f(1)
+	}
+	for i := 0; i < 5; i++ {
+		f(2)
+	}
+	for i := 3; i < 6; i++ {
+		f(i)
+		f(i)
+	}
+	keys := HotKeys(ctx, FuncID("hot"))
+	if len(keys) != 2 || keys[0].Key != "1" || keys[0].Count != 9 || keys[0].Error != 0 {
+		t.Fatalf("hot keys = %+v", keys)
+	}
+	if HotKeys(ctx, FuncID("missing")) != nil {
+		t.Fatal("hot keys of a missing cache")
+	}
+}
@@ -0,0 +1,151 @@
+package ctxcache
+
+import (
+	"cmp"
+	"context"
+	"iter"
+	"slices"
+	"time"
+)
+
+// Entries returns an iterator over the entries cached in ctx under ctxKey,
+// leaving out errors cached by CacheErrors. The cache is read-locked while
+// iterating, so the loop body must not call into the same cache.
+func Entries[K comparable, V any](ctx context.Context, ctxKey FuncID) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		c, ok := cacheValue(ctx, ctxKey).(*cache[K, V])
+		if !ok {
+			return
+		}
+		c.lock.RLock()
+		defer c.lock.RUnlock()
+		now := time.Now().UnixNano()
+		for k, e := range c.data.all() {
+			if c.expired(e, now) || e.err() != nil {
+				continue
+			}
+			v, alive := e.get()
+			if !alive {
+				continue
+			}
+			if !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// GetRange returns an iterator over the entries cached in ctx under ctxKey
+// with keys between lo and hi included, in key order. It walks only the
+// range with Backend(Ordered), and sorts the matching entries otherwise.
+// Unlike Entries, it collects the matches before yielding them, so the loop
+// body may call the cache, to look up neighbouring keys for example.
+func GetRange[K cmp.Ordered, V any](ctx context.Context, ctxKey FuncID, lo, hi K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		c, ok := cacheValue(ctx, ctxKey).(*cache[K, V])
+		if !ok {
+			return
+		}
+		for _, e := range rangeOf(c, lo, hi) {
+			if !yield(e.key, e.value) {
+				return
+			}
+		}
+	}
+}
+
+// rangeOf returns the live entries with keys between lo and hi included, in
+// key order.
+func rangeOf[K cmp.Ordered, V any](c *cache[K, V], lo, hi K) []entry[K, V] {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	now := time.Now().UnixNano()
+	var matches []entry[K, V]
+	visit := func(k K, e *entry[K, V]) bool {
+		v, alive := e.get()
+		if alive && !c.expired(e, now) && e.err() == nil {
+			matches = append(matches, entry[K, V]{key: k, value: v})
+		}
+		return true
+	}
+	if m, ok := c.data.(*orderedMap[K, V]); ok {
+		m.ascend(m.root, &lo, &hi, visit)
+		return matches
+	}
+	var keys []K
+	for k := range c.data.all() {
+		if cmp.Compare(k, lo) >= 0 && cmp.Compare(k, hi) <= 0 {
+			keys = append(keys, k)
+		}
+	}
+	slices.SortFunc(keys, cmp.Compare[K])
+	for _, k := range keys {
+		e, _ := c.data.load(k)
+		visit(k, e)
+	}
+	return matches
+}
+
+// EntryMeta describes a cached entry.
+type EntryMeta struct {
+	Created time.Time `json:"created"`
+	// LastAccess and Hits are recorded only with TrackAccess, MaxIdle or
+	// RefreshAhead, and are zero otherwise.
+	LastAccess time.Time `json:"last_access"`
+	Hits       uint64    `json:"hits"`
+	// Expires is the absolute expiry set by TTL, zero if none.
+	Expires time.Time `json:"expires"`
+}
+
+// TrackAccess records the time of the last read and the number of reads of
+// every entry, as reported by Metadata. It costs a clock read and two
+// shared writes per hit, so it is off unless MaxIdle or RefreshAhead, which
+// need it too, are set.
+func TrackAccess() Option {
+	return func(o *options) {
+		o.trackAccess = true
+	}
+}
+
+func (o *options) tracksAccess() bool {
+	return o.trackAccess || o.maxIdle > 0 || o.refreshInterval > 0
+}
+
+// Metadata returns an iterator over the metadata of the entries cached in
+// ctx under ctxKey, to find hot and cold keys. The same locking rules as
+// Entries apply.
+func Metadata[K comparable, V any](ctx context.Context, ctxKey FuncID) iter.Seq2[K, EntryMeta] {
+	return func(yield func(K, EntryMeta) bool) {
+		c, ok := cacheValue(ctx, ctxKey).(*cache[K, V])
+		if !ok {
+			return
+		}
+		c.lock.RLock()
+		defer c.lock.RUnlock()
+		now := time.Now().UnixNano()
+		for k, e := range c.data.all() {
+			if c.expired(e, now) {
+				continue
+			}
+			if !yield(k, e.meta()) {
+				return
+			}
+		}
+	}
+}
+
+func (e *entry[K, V]) meta() EntryMeta {
+	m := EntryMeta{Created: time.Unix(0, e.created)}
+	x := e.x
+	if x == nil {
+		return m
+	}
+	if accessed := x.accessed.Load(); accessed != 0 {
+		m.LastAccess = time.Unix(0, accessed)
+		m.Hits = x.hits.Load()
+	}
+	if x.expiry != 0 {
+		m.Expires = time.Unix(0, x.expiry)
+	}
+	return m
+}
@@ -0,0 +1,48 @@
+package ctxcache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEntries(t *testing.T) {
+	double := func(n int) int { return n * 2 }
+	ctx := WithCache(context.Background(), FuncID("double"), double)
+	f, _ := FromContext(ctx, FuncID("double"), double)
+	f(1)
+	f(2)
+
+	got := map[int]int{}
+	for k, v := range Entries[int, int](ctx, FuncID("double")) {
+		got[k] = v
+	}
+	if len(got) != 2 || got[1] != 2 || got[2] != 4 {
+		t.Fatalf("entries = %v", got)
+	}
+	for range Entries[string, int](ctx, FuncID("double")) {
+		t.Fatal("unexpected entry for mismatched types")
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	double := func(n int) int { return n * 2 }
+	ctx := WithCache(context.Background(), FuncID("double"), double, TTL(time.Hour), TrackAccess())
+	f, _ := FromContext(ctx, FuncID("double"), double)
+	f(1)
+	f(1)
+	f(1)
+	f(2)
+
+	metas := map[int]EntryMeta{}
+	for k, m := range Metadata[int, int](ctx, FuncID("double")) {
+		metas[k] = m
+	}
+	hot, cold := metas[1], metas[2]
+	if hot.Hits != 2 || cold.Hits != 0 {
+		t.Fatalf("hits = %d, %d", hot.Hits, cold.Hits)
+	}
+	if hot.LastAccess.Before(hot.Created) || hot.Expires.Sub(hot.Created) != time.Hour {
+		t.Fatalf("meta = %+v", hot)
+	}
+}
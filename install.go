@@ -0,0 +1,25 @@
+package ctxcache
+
+import "context"
+
+// Installer installs caches in a context, typically once per request.
+type Installer interface {
+	Install(ctx context.Context) context.Context
+}
+
+// InstallerFunc adapts a function to an Installer.
+type InstallerFunc func(ctx context.Context) context.Context
+
+func (f InstallerFunc) Install(ctx context.Context) context.Context {
+	return f(ctx)
+}
+
+// WithCaches installs every installer in ctx, in order:
+//
+//	ctx = ctxcache.WithCaches(ctx, userCache, orderCache, settingsCache)
+func WithCaches(ctx context.Context, installers ...Installer) context.Context {
+	for _, in := range installers {
+		ctx = in.Install(ctx)
+	}
+	return ctx
+}
@@ -0,0 +1,21 @@
+package ctxcache
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithCaches(t *testing.T) {
+	double := Define(FuncID("double"), func(n int) int { return n * 2 })
+	var order []string
+	ctx := WithCaches(context.Background(),
+		double,
+		InstallerFunc(func(ctx context.Context) context.Context {
+			order = append(order, "func")
+			return ctx
+		}),
+	)
+	if _, ok := FromContext(ctx, double.ID(), func(n int) int { return n * 2 }); !ok || len(order) != 1 {
+		t.Fatalf("installers not applied: %v", order)
+	}
+}
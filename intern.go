@@ -0,0 +1,32 @@
+package ctxcache
+
+import "unique"
+
+// InternKeys interns stored keys with the unique package, so equal keys
+// repeated across caches and contexts share their memory. It pays off with
+// string-heavy keys held by many caches at once. The unique handle of each
+// key is kept while the key is cached, so that equal keys stored meanwhile
+// share its copy.
+func InternKeys() Option {
+	return func(o *options) {
+		o.internKeys = true
+	}
+}
+
+// intern returns the canonical copy of k and holds its handle until
+// unintern. c.lock must be held for writing.
+func (c *cache[K, V]) intern(k K) K {
+	h := unique.Make(k)
+	if c.handles == nil {
+		c.handles = make(map[K]unique.Handle[K])
+	}
+	k = h.Value()
+	c.handles[k] = h
+	return k
+}
+
+// unintern releases the handle of k, if held. c.lock must be held for
+// writing.
+func (c *cache[K, V]) unintern(k K) {
+	delete(c.handles, k)
+}
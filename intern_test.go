@@ -0,0 +1,43 @@
+package ctxcache
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestInternKeys(t *testing.T) {
+	upper := func(s string) string { return strings.ToUpper(s) }
+	a := WithCache(context.Background(), FuncID("upper"), upper, InternKeys())
+	b := WithCache(context.Background(), FuncID("upper"), upper, InternKeys())
+	f, _ := FromContext(a, FuncID("upper"), upper)
+	g, _ := FromContext(b, FuncID("upper"), upper)
+	f(strings.Repeat("key", 10))
+	runtime.GC()
+	runtime.GC()
+	g(strings.Repeat("key", 10))
+
+	var keys []string
+	for _, ctx := range []context.Context{a, b} {
+		for _, e := range cacheValue(ctx, FuncID("upper")).(*cache[string, string]).data.all() {
+			keys = append(keys, e.key)
+		}
+	}
+	if len(keys) != 2 || unsafe.StringData(keys[0]) != unsafe.StringData(keys[1]) {
+		t.Fatal("keys not interned")
+	}
+	if f(strings.Repeat("key", 10)) != strings.Repeat("KEY", 10) {
+		t.Fatal("interned key missed")
+	}
+	c := cacheValue(a, FuncID("upper")).(*cache[string, string])
+	c.set(a, strings.Repeat("key", 10), "overwritten", nil, 0)
+	if n := len(c.handles); n != 1 {
+		t.Fatalf("%d handles held after overwrite", n)
+	}
+	Invalidate(a, FuncID("upper"), strings.Repeat("key", 10))
+	if n := len(c.handles); n != 0 {
+		t.Fatalf("%d handles held after invalidation", n)
+	}
+}
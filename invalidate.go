@@ -0,0 +1,60 @@
+package ctxcache
+
+import (
+	"context"
+	"slices"
+)
+
+// Invalidate drops key from the cache installed in ctx under ctxKey. It
+// reports whether an entry was removed.
+func Invalidate(ctx context.Context, ctxKey FuncID, key any) bool {
+	c, ok := cacheValue(ctx, ctxKey).(anyCache)
+	if !ok {
+		return false
+	}
+	return c.invalidate(key)
+}
+
+// Purge drops every entry of the cache installed in ctx under ctxKey.
+func Purge(ctx context.Context, ctxKey FuncID) {
+	if c, ok := cacheValue(ctx, ctxKey).(anyCache); ok {
+		c.release()
+	}
+}
+
+// InGroup adds the cache to the named invalidation groups.
+func InGroup(groups ...string) Option {
+	return func(o *options) {
+		o.groups = append(o.groups, groups...)
+	}
+}
+
+// InvalidateGroup purges every cache installed in ctx that belongs to group.
+func InvalidateGroup(ctx context.Context, group string) {
+	allCaches(ctx, func(c anyCache) {
+		if c.inGroup(group) {
+			c.release()
+		}
+	})
+}
+
+func (c *cache[K, V]) invalidate(key any) bool {
+	k, ok := key.(K)
+	if !ok {
+		return false
+	}
+	c.lock.Lock()
+	e, ok := c.data.load(k)
+	if ok {
+		c.remove(e)
+	}
+	c.lock.Unlock()
+	for d := range c.takeDependents(k) {
+		d.c.invalidate(d.key)
+	}
+	return ok
+}
+
+func (c *cache[K, V]) inGroup(group string) bool {
+	return slices.Contains(c.opts.groups, group)
+}
@@ -0,0 +1,49 @@
+package ctxcache
+
+import (
+	"context"
+	"testing"
+)
+
+func TestInvalidate(t *testing.T) {
+	loads := 0
+	inc := func(n int) int {
+		loads++
+		return n + 1
+	}
+	ctx := WithCache(context.Background(), FuncID("inc"), inc)
+	f, _ := FromContext(ctx, FuncID("inc"), inc)
+	f(1)
+	f(2)
+	if !Invalidate(ctx, FuncID("inc"), 1) || Invalidate(ctx, FuncID("inc"), "1") {
+		t.Fatal("unexpected Invalidate result")
+	}
+	f(1)
+	f(2)
+	if loads != 3 {
+		t.Fatalf("loads = %d, want 3", loads)
+	}
+}
+
+func TestInvalidateGroup(t *testing.T) {
+	loads := 0
+	inc := func(n int) int {
+		loads++
+		return n + 1
+	}
+	ctx := WithCache(context.Background(), FuncID("product"), inc, InGroup("catalog"))
+	ctx = WithCache(ctx, FuncID("price"), inc, InGroup("catalog", "pricing"))
+	ctx = WithCache(ctx, FuncID("user"), inc)
+	for _, id := range []FuncID{"product", "price", "user"} {
+		f, _ := FromContext(ctx, id, inc)
+		f(1)
+	}
+	InvalidateGroup(ctx, "catalog")
+	for _, id := range []FuncID{"product", "price", "user"} {
+		f, _ := FromContext(ctx, id, inc)
+		f(1)
+	}
+	if loads != 5 {
+		t.Fatalf("loads = %d, want 5", loads)
+	}
+}
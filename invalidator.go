@@ -0,0 +1,84 @@
+package ctxcache
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Invalidator broadcasts invalidations between the instances sharing a
+// tier, such as the Shared stores of several replicas. Keys are formatted
+// with fmt.Sprint.
+type Invalidator interface {
+	// Publish announces to every instance that key of the cache id was
+	// invalidated.
+	Publish(ctx context.Context, id FuncID, key string) error
+	// Subscribe calls fn with every invalidation published, including the
+	// instance's own, until ctx is done or the subscription fails.
+	Subscribe(ctx context.Context, fn func(id FuncID, key string)) error
+}
+
+// CoherentStore is a Store whose deletes and writes are broadcast with an
+// Invalidator as invalidations applied on every instance, keeping
+// multi-replica deployments coherent. Writes of loaded values are not
+// broadcast, as other instances cannot hold a newer value.
+type CoherentStore[K comparable, V any] struct {
+	Store[K, V]
+	inv   Invalidator
+	id    FuncID
+	parse func(string) (K, error)
+}
+
+// Coherent wraps store so that deletes and writes are published with inv
+// under id.
+// parse turns published keys back into keys of the store. Call Listen to
+// apply the deletes of other instances.
+func Coherent[K comparable, V any](store Store[K, V], inv Invalidator, id FuncID, parse func(string) (K, error)) *CoherentStore[K, V] {
+	return &CoherentStore[K, V]{Store: store, inv: inv, id: id, parse: parse}
+}
+
+// Delete deletes key from the store and publishes its invalidation.
+func (s *CoherentStore[K, V]) Delete(ctx context.Context, key K) error {
+	if err := s.Store.Delete(ctx, key); err != nil {
+		return err
+	}
+	return s.inv.Publish(ctx, s.id, fmt.Sprint(key))
+}
+
+// Set publishes the invalidation of key, then writes value to the store, so
+// that other instances drop the value they hold, as after PutThrough. With
+// an Invalidator delivering asynchronously, the invalidation may also drop
+// value from this instance's store, which then loads it again.
+func (s *CoherentStore[K, V]) Set(ctx context.Context, key K, value V) error {
+	if err := s.inv.Publish(ctx, s.id, fmt.Sprint(key)); err != nil {
+		return err
+	}
+	return s.Store.Set(ctx, key, value)
+}
+
+// Listen deletes from the store the keys invalidated by any instance until
+// ctx is done or the subscription fails. Keys parse rejects are skipped.
+func (s *CoherentStore[K, V]) Listen(ctx context.Context) error {
+	return s.inv.Subscribe(ctx, func(id FuncID, key string) {
+		if id != s.id {
+			return
+		}
+		if k, err := s.parse(key); err == nil {
+			s.Store.Delete(ctx, k)
+		}
+	})
+}
+
+func (s *CoherentStore[K, V]) token() uint64 {
+	if ts, ok := s.Store.(tokenStore[K, V]); ok {
+		return ts.token()
+	}
+	return 0
+}
+
+func (s *CoherentStore[K, V]) setIfToken(ctx context.Context, key K, value V, token uint64, loadTime time.Duration) error {
+	if ts, ok := s.Store.(tokenStore[K, V]); ok {
+		return ts.setIfToken(ctx, key, value, token, loadTime)
+	}
+	return s.Store.Set(ctx, key, value)
+}
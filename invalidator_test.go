@@ -0,0 +1,132 @@
+package ctxcache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// bus is an in-process Invalidator.
+type bus struct {
+	lock sync.Mutex
+	subs []func(FuncID, string)
+	subd sync.WaitGroup
+}
+
+func (b *bus) Publish(_ context.Context, id FuncID, key string) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for _, fn := range b.subs {
+		fn(id, key)
+	}
+	return nil
+}
+
+func (b *bus) Subscribe(ctx context.Context, fn func(FuncID, string)) error {
+	b.lock.Lock()
+	b.subs = append(b.subs, fn)
+	b.lock.Unlock()
+	b.subd.Done()
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestCoherentStore(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	b := &bus{}
+	var replicas []*CoherentStore[int, int]
+	for range 2 {
+		s := Coherent[int, int](NewShared[int, int](0), b, FuncID("square"), strconv.Atoi)
+		b.subd.Add(1)
+		go s.Listen(ctx)
+		replicas = append(replicas, s)
+	}
+	b.subd.Wait()
+
+	b.Publish(ctx, FuncID("other"), "1")
+	for _, s := range replicas {
+		s.Store.Set(ctx, 1, 1)
+		s.Store.Set(ctx, 2, 4)
+	}
+	if err := replicas[0].Delete(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	for i, s := range replicas {
+		if _, ok, _ := s.Get(ctx, 1); ok {
+			t.Fatalf("replica %d kept the invalidated key", i)
+		}
+		if _, ok, _ := s.Get(ctx, 2); !ok {
+			t.Fatalf("replica %d dropped another key", i)
+		}
+	}
+
+	square := func(n int) int { return n * n }
+	cctx := WithCache(ctx, FuncID("square"), square, WithStore[int, int](replicas[1]))
+	f, _ := FromContext(cctx, FuncID("square"), square)
+	if f(3) != 9 {
+		t.Fatal("unexpected value")
+	}
+	if v, ok, _ := replicas[1].Get(ctx, 3); !ok || v != 9 {
+		t.Fatal("loaded value not written through the coherent store")
+	}
+}
+
+func TestCoherentStorePutThrough(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	b := &bus{}
+	var lock sync.Mutex
+	db := map[int]int{1: 1}
+	load := func(n int) int {
+		lock.Lock()
+		defer lock.Unlock()
+		return db[n]
+	}
+	sink := func(k, v int) error {
+		lock.Lock()
+		defer lock.Unlock()
+		db[k] = v
+		return nil
+	}
+	var stores []*CoherentStore[int, int]
+	for range 2 {
+		s := Coherent[int, int](NewShared[int, int](0), b, FuncID("db"), strconv.Atoi)
+		b.subd.Add(1)
+		go s.Listen(ctx)
+		stores = append(stores, s)
+	}
+	b.subd.Wait()
+	request := func(i int) CacheFunc[int, int] {
+		f, _ := FromContext(WithCache(ctx, FuncID("db"), load, WithStore[int, int](stores[i])), FuncID("db"), load)
+		return f
+	}
+
+	request(1)(1)
+	put := WithCache(ctx, FuncID("db"), load, WithStore[int, int](stores[0]))
+	if err := PutThrough(put, FuncID("db"), 1, 2, sink); err != nil {
+		t.Fatal(err)
+	}
+	if v := request(1)(1); v != 2 {
+		t.Fatalf("other instance read %d after PutThrough", v)
+	}
+	if v, ok, _ := stores[0].Get(ctx, 1); !ok || v != 2 {
+		t.Fatalf("writing instance holds %d, %v", v, ok)
+	}
+}
+
+func TestCoherentStoreErrors(t *testing.T) {
+	store := Coherent[int, int](downStore{}, &bus{}, FuncID("square"), strconv.Atoi)
+	square := func(n int) int { return n * n }
+	ctx := WithCache(context.Background(), FuncID("square"), square, WithStore[int, int](store))
+	f, _ := FromContext(ctx, FuncID("square"), square)
+	f(2)
+	if err := store.setIfToken(ctx, 2, 4, 0, 0); !errors.Is(err, errDown) {
+		t.Fatalf("setIfToken err = %v", err)
+	}
+	if err := Healthy(ctx); !errors.Is(err, errDown) {
+		t.Fatalf("Healthy = %v, want the store error", err)
+	}
+}
@@ -0,0 +1,48 @@
+package ctxcache
+
+import "fmt"
+
+// Key2 is a composite key for loaders taking two arguments.
+type Key2[A, B comparable] struct {
+	V1 A
+	V2 B
+}
+
+// NewKey2 returns the Key2 of a and b.
+func NewKey2[A, B comparable](a A, b B) Key2[A, B] {
+	return Key2[A, B]{V1: a, V2: b}
+}
+
+func (k Key2[A, B]) String() string {
+	return fmt.Sprintf("(%v, %v)", k.V1, k.V2)
+}
+
+// Key3 is a composite key for loaders taking three arguments.
+type Key3[A, B, C comparable] struct {
+	V1 A
+	V2 B
+	V3 C
+}
+
+// NewKey3 returns the Key3 of a, b and c.
+func NewKey3[A, B, C comparable](a A, b B, c C) Key3[A, B, C] {
+	return Key3[A, B, C]{V1: a, V2: b, V3: c}
+}
+
+func (k Key3[A, B, C]) String() string {
+	return fmt.Sprintf("(%v, %v, %v)", k.V1, k.V2, k.V3)
+}
+
+// Func2 adapts a two-argument loader to a CacheFunc keyed by Key2.
+func Func2[A, B comparable, V any](f func(A, B) V) CacheFunc[Key2[A, B], V] {
+	return func(k Key2[A, B]) V {
+		return f(k.V1, k.V2)
+	}
+}
+
+// Func3 adapts a three-argument loader to a CacheFunc keyed by Key3.
+func Func3[A, B, C comparable, V any](f func(A, B, C) V) CacheFunc[Key3[A, B, C], V] {
+	return func(k Key3[A, B, C]) V {
+		return f(k.V1, k.V2, k.V3)
+	}
+}
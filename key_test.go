@@ -0,0 +1,39 @@
+package ctxcache
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestKey2(t *testing.T) {
+	loads := 0
+	repeat := Func2(func(s string, n int) string {
+		loads++
+		return strings.Repeat(s, n)
+	})
+	ctx := WithCache(context.Background(), FuncID("repeat"), repeat)
+	f, _ := FromContext(ctx, FuncID("repeat"), repeat)
+	if f(NewKey2("ab", 2)) != "abab" || f(NewKey2("ab", 2)) != "abab" || f(NewKey2("a", 3)) != "aaa" {
+		t.Fatal("wrong result")
+	}
+	if loads != 2 {
+		t.Fatalf("loads = %d, want 2", loads)
+	}
+	if s := NewKey2("ab", 2).String(); s != "(ab, 2)" {
+		t.Fatalf("String() = %q", s)
+	}
+}
+
+func TestKey3(t *testing.T) {
+	add := Func3(func(a, b, c int) int { return a + b + c })
+	if add(NewKey3(1, 2, 3)) != 6 {
+		t.Fatal("wrong sum")
+	}
+	if NewKey3(1, 2, 3) == NewKey3(3, 2, 1) {
+		t.Fatal("keys collide")
+	}
+	if s := NewKey3(1, "b", true).String(); s != "(1, b, true)" {
+		t.Fatalf("String() = %q", s)
+	}
+}
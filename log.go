@@ -0,0 +1,94 @@
+package ctxcache
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// WithLogger logs misses, loads and evictions of the cache to logger at debug level,
+// so the output can be toggled through the logger's handler.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WarnIfSlower logs a warning to logger whenever a single load of the
+// cache takes longer than threshold.
+func WarnIfSlower(threshold time.Duration, logger *slog.Logger) Option {
+	return func(o *options) {
+		o.slowThreshold = threshold
+		o.slowLogger = logger
+	}
+}
+
+// RedactKey makes logs, DumpJSON and diagnostics show keys as redact(k)
+// instead of fmt.Sprint(k), so keys such as emails or tokens do not leak.
+func RedactKey[K comparable](redact func(K) string) Option {
+	return func(o *options) {
+		o.redact = redact
+	}
+}
+
+func (c *cache[K, V]) keyString(k K) string {
+	if c.redactFn != nil {
+		return c.redactFn(k)
+	}
+	return fmt.Sprint(k)
+}
+
+func (c *cache[K, V]) logMiss(ctx context.Context, k K) {
+	if c.opts.logger == nil {
+		return
+	}
+	c.opts.logger.LogAttrs(ctx, slog.LevelDebug, "ctxcache miss",
+		slog.String("func_id", string(c.id)),
+		slog.String("key", c.keyString(k)),
+	)
+}
+
+func (c *cache[K, V]) logLoad(ctx context.Context, k K, d time.Duration, err error) {
+	if c.opts.slowLogger != nil && d > c.opts.slowThreshold {
+		c.opts.slowLogger.LogAttrs(ctx, slog.LevelWarn, "ctxcache slow load",
+			slog.String("func_id", string(c.id)),
+			slog.String("key", c.keyString(k)),
+			slog.Duration("duration", d),
+			slog.Duration("threshold", c.opts.slowThreshold),
+		)
+	}
+	if c.opts.logger == nil {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("func_id", string(c.id)),
+		slog.String("key", c.keyString(k)),
+		slog.Duration("duration", d),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	c.opts.logger.LogAttrs(ctx, slog.LevelDebug, "ctxcache load", attrs...)
+}
+
+func (c *cache[K, V]) logStoreError(ctx context.Context, k K, err error) {
+	if c.opts.logger == nil {
+		return
+	}
+	c.opts.logger.LogAttrs(ctx, slog.LevelWarn, "ctxcache store error",
+		slog.String("func_id", string(c.id)),
+		slog.String("key", c.keyString(k)),
+		slog.Any("error", err),
+	)
+}
+
+func (c *cache[K, V]) logEvict(ctx context.Context, k K) {
+	if c.opts.logger == nil {
+		return
+	}
+	c.opts.logger.LogAttrs(ctx, slog.LevelDebug, "ctxcache evict",
+		slog.String("func_id", string(c.id)),
+		slog.String("key", c.keyString(k)),
+	)
+}
@@ -0,0 +1,71 @@
+package ctxcache
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	ctx := WithCache(context.Background(), FuncID("double"), func(n int) int { return n * 2 }, WithLogger(logger))
+	double, _ := FromContext(ctx, FuncID("double"), func(n int) int { return n * 2 })
+	double(1)
+	double(1)
+
+	out := buf.String()
+	if strings.Count(out, "ctxcache miss") != 1 || strings.Count(out, "ctxcache load") != 1 {
+		t.Fatalf("unexpected log output: %s", out)
+	}
+	if !strings.Contains(out, "func_id=double") || !strings.Contains(out, "key=1") {
+		t.Fatalf("missing fields: %s", out)
+	}
+}
+
+func TestWarnIfSlower(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	slow := func(n int) int {
+		if n > 0 {
+			time.Sleep(5 * time.Millisecond)
+		}
+		return n
+	}
+	ctx := WithCache(context.Background(), FuncID("slow"), slow, WarnIfSlower(time.Millisecond, logger))
+	f, _ := FromContext(ctx, FuncID("slow"), slow)
+	f(0)
+	if buf.Len() != 0 {
+		t.Fatalf("unexpected warning: %s", buf.String())
+	}
+	f(1)
+	if out := buf.String(); !strings.Contains(out, "level=WARN") || !strings.Contains(out, "key=1") {
+		t.Fatalf("missing slow load warning: %s", out)
+	}
+}
+
+func TestRedactKey(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	load := func(email string) int { return len(email) }
+	redact := func(email string) string { return "REDACTED" }
+	ctx := WithCache(context.Background(), FuncID("email"), load, WithLogger(logger), RedactKey(redact))
+	f, _ := FromContext(ctx, FuncID("email"), load)
+	f("alice@example.com")
+
+	dump, err := DumpJSON(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, out := range []string{buf.String(), string(dump)} {
+		if strings.Contains(out, "alice") || !strings.Contains(out, "REDACTED") {
+			t.Fatalf("key not redacted: %s", out)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package ctxcache
+
+import "context"
+
+// Bind binds receiver to a method expression such as (*Service).GetUser.
+func Bind[R any, K comparable, V any](receiver R, method func(R, K) V) CacheFunc[K, V] {
+	return func(k K) V {
+		return method(receiver, k)
+	}
+}
+
+// BindMethod installs method bound to receiver under ctxKey:
+//
+//	ctx = ctxcache.BindMethod(ctx, "user", svc, (*Service).GetUser)
+func BindMethod[R any, K comparable, V any](ctx context.Context, ctxKey FuncID, receiver R, method func(R, K) V, opts ...Option) context.Context {
+	return WithCache(ctx, ctxKey, Bind(receiver, method), opts...)
+}
+
+// FromContextMethod returns the method installed by BindMethod, or method
+// bound to receiver if there is none. A service can expose cached calls
+// with it:
+//
+//	func (s *Service) CachedUser(ctx context.Context, id int) *User {
+//		f, _ := ctxcache.FromContextMethod(ctx, "user", s, (*Service).GetUser)
+//		return f(id)
+//	}
+func FromContextMethod[R any, K comparable, V any](ctx context.Context, ctxKey FuncID, receiver R, method func(R, K) V) (CacheFunc[K, V], bool) {
+	return FromContext(ctx, ctxKey, Bind(receiver, method))
+}
@@ -0,0 +1,28 @@
+package ctxcache
+
+import (
+	"context"
+	"testing"
+)
+
+type userService struct {
+	prefix string
+	calls  int
+}
+
+func (s *userService) name(id int) string {
+	s.calls++
+	return s.prefix + string(rune('a'+id))
+}
+
+func TestBindMethod(t *testing.T) {
+	svc := &userService{prefix: "user-"}
+	ctx := BindMethod(context.Background(), FuncID("name"), svc, (*userService).name)
+	f, ok := FromContextMethod(ctx, FuncID("name"), svc, (*userService).name)
+	if !ok {
+		t.Fatal("method not installed")
+	}
+	if f(1) != "user-b" || f(1) != "user-b" || svc.calls != 1 {
+		t.Fatalf("calls = %d", svc.calls)
+	}
+}
@@ -0,0 +1,100 @@
+package ctxcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ErrSharedStore is returned by the calls of a cache installed with
+// WithStore in a Namespace context, as its Store is shared by every
+// namespace.
+var ErrSharedStore = errors.New("ctxcache: cannot partition a cache with a Store by Namespace")
+
+type namespaceKey struct{}
+
+// Namespace returns a context in which every cache lookup is partitioned by
+// ns. Caches installed in ctx keep a separate set of entries per namespace,
+// so values loaded for one tenant are never returned to another.
+//
+// All functions taking a FuncID resolve the partition of the namespace in
+// their context. Release and the cancellation of the install context drop
+// every partition, and Events, DumpJSON, GetReport and Healthy cover them.
+// Record, Replay and Deterministic key their values by namespace too. As a
+// Store is keyed by K alone, calls of a cache installed with WithStore fail
+// with ErrSharedStore in a namespace, returning the zero value or the
+// Fallback from FromContext.
+func Namespace(ctx context.Context, ns string) context.Context {
+	return context.WithValue(ctx, namespaceKey{}, ns)
+}
+
+// cacheValue returns the cache installed in ctx under id, resolved to the
+// partition of the context's namespace.
+func cacheValue(ctx context.Context, id FuncID) any {
+	v := ctx.Value(funcKey{id})
+	if ns, ok := ctx.Value(namespaceKey{}).(string); ok && ns != "" {
+		if c, ok := v.(anyCache); ok {
+			return c.space(ns)
+		}
+	}
+	return v
+}
+
+func (c *cache[K, V]) space(ns string) anyCache {
+	if s, ok := c.spaces.Load(ns); ok {
+		return s.(*cache[K, V])
+	}
+	c.spacesLock.Lock()
+	defer c.spacesLock.Unlock()
+	if s, ok := c.spaces.Load(ns); ok {
+		return s.(*cache[K, V])
+	}
+	s := newCache(c.root, c.id, c.currentLoader(), c.opts)
+	s.ns = ns
+	s.parent = c
+	if s.store != nil {
+		s.store = nil
+		s.refused = fmt.Errorf("%w: %s", ErrSharedStore, c.id)
+	}
+	c.spaces.Store(ns, s)
+	return s
+}
+
+func (c *cache[K, V]) partitions(fn func(anyCache)) {
+	c.eachSpace(func(s *cache[K, V]) {
+		fn(s)
+	})
+}
+
+// withPartitions extends fn to the Namespace partitions of each cache.
+func withPartitions(fn func(anyCache)) func(anyCache) {
+	return func(c anyCache) {
+		fn(c)
+		c.partitions(fn)
+	}
+}
+
+// name is the FuncID of c, followed by its namespace for partitions.
+func (c *cache[K, V]) name() string {
+	if c.ns == "" {
+		return string(c.id)
+	}
+	return string(c.id) + "/" + c.ns
+}
+
+// recordKey formats k for Record, Replay and Deterministic, prefixed with
+// the quoted namespace of partitions.
+func (c *cache[K, V]) recordKey(k K) string {
+	if c.ns == "" {
+		return fmt.Sprint(k)
+	}
+	return strconv.Quote(c.ns) + ":" + fmt.Sprint(k)
+}
+
+func (c *cache[K, V]) eachSpace(fn func(*cache[K, V])) {
+	c.spaces.Range(func(_, s any) bool {
+		fn(s.(*cache[K, V]))
+		return true
+	})
+}
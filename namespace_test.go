@@ -0,0 +1,116 @@
+package ctxcache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNamespace(t *testing.T) {
+	loads := 0
+	inc := func(n int) int {
+		loads++
+		return n + 1
+	}
+	ctx := WithCache(context.Background(), FuncID("inc"), inc)
+	a := Namespace(ctx, "tenant-a")
+	b := Namespace(ctx, "tenant-b")
+	for _, nctx := range []context.Context{a, b, a, b} {
+		f, ok := FromContext(nctx, FuncID("inc"), inc)
+		if !ok {
+			t.Fatal("cache not installed")
+		}
+		f(1)
+	}
+	if loads != 2 {
+		t.Fatalf("loads = %d, want one per namespace", loads)
+	}
+	if s, _ := GetStats(a, FuncID("inc")); s.Hits != 1 || s.Misses != 1 {
+		t.Fatalf("tenant-a stats = %+v", s)
+	}
+	if s, _ := GetStats(ctx, FuncID("inc")); s.Hits != 0 || s.Misses != 0 {
+		t.Fatalf("root stats = %+v", s)
+	}
+
+	if !Invalidate(a, FuncID("inc"), 1) || Invalidate(a, FuncID("inc"), 1) {
+		t.Fatal("unexpected Invalidate result")
+	}
+	if !Invalidate(b, FuncID("inc"), 1) {
+		t.Fatal("tenant-b entry dropped by tenant-a invalidation")
+	}
+
+	f, _ := FromContext(b, FuncID("inc"), inc)
+	f(2)
+	Release(ctx)
+	if Invalidate(b, FuncID("inc"), 2) {
+		t.Fatal("Release kept namespaced entries")
+	}
+}
+
+func TestNamespaceCoverage(t *testing.T) {
+	inc := func(n int) int { return n + 1 }
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctx = WithCache(ctx, FuncID("inc"), inc, TTL(time.Millisecond), Janitor(time.Millisecond))
+	a := Namespace(ctx, "tenant-a")
+	subCtx, stop := context.WithCancel(ctx)
+	events := Events(subCtx, 16)
+	f, _ := FromContext(a, FuncID("inc"), inc)
+	f(1)
+	stop()
+	n := 0
+	for range events {
+		n++
+	}
+	if n != 2 {
+		t.Fatalf("received %d events of the partition, want 2", n)
+	}
+
+	r := GetReport(ctx)
+	if len(r.Caches) != 2 || r.Caches[1].Namespace != "tenant-a" || r.Caches[1].Loads != 1 {
+		t.Fatalf("report = %+v", r)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if s, _ := GetStats(a, FuncID("inc")); s.Entries == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("partition not swept by the janitor")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestNamespaceRecordKeys(t *testing.T) {
+	rec := NewRecording()
+	inc := func(n int) int { return n + 1 }
+	ctx := WithCache(context.Background(), FuncID("inc"), inc, Record(rec))
+	f, _ := FromContext(Namespace(ctx, "tenant-a"), FuncID("inc"), inc)
+	f(1)
+
+	ctx = WithCache(context.Background(), FuncID("inc"), inc, Replay(rec))
+	g, _ := FromContextE(Namespace(ctx, "tenant-b"), FuncID("inc"), plainLoader(inc))
+	if _, err := g(1); !errors.Is(err, ErrNotRecorded) {
+		t.Fatalf("tenant-b replayed the load of tenant-a: err = %v", err)
+	}
+	g, _ = FromContextE(Namespace(ctx, "tenant-a"), FuncID("inc"), plainLoader(inc))
+	if v, err := g(1); v != 2 || err != nil {
+		t.Fatalf("g(1) = %d, %v", v, err)
+	}
+}
+
+func TestNamespaceStore(t *testing.T) {
+	inc := func(_ context.Context, n int) (int, error) { return n + 1, nil }
+	shared := NewShared[int, int](0)
+	ctx := WithCacheE(context.Background(), FuncID("inc"), inc, WithStore[int, int](shared))
+	f, _ := FromContextE(Namespace(ctx, "tenant-a"), FuncID("inc"), inc)
+	if _, err := f(1); !errors.Is(err, ErrSharedStore) {
+		t.Fatalf("partitioned call err = %v", err)
+	}
+	if _, ok, _ := shared.Get(ctx, 1); ok {
+		t.Fatal("partition wrote the shared store")
+	}
+}
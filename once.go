@@ -0,0 +1,28 @@
+package ctxcache
+
+import (
+	"context"
+	"sync"
+)
+
+// WithOnce installs a per-context sync.OnceValue of f under ctxKey, for
+// request-scoped singletons such as the current user's settings. f runs at
+// most once per installation, even under concurrent calls.
+func WithOnce[V any](ctx context.Context, ctxKey FuncID, f func() V, opts ...Option) context.Context {
+	once := sync.OnceValue(f)
+	return WithCache(ctx, ctxKey, func(struct{}) V {
+		return once()
+	}, opts...)
+}
+
+// FromContextOnce returns the function installed by WithOnce, or f if there
+// is none.
+func FromContextOnce[V any](ctx context.Context, ctxKey FuncID, f func() V) (func() V, bool) {
+	get, ok := FromContext[struct{}, V](ctx, ctxKey, nil)
+	if !ok {
+		return f, false
+	}
+	return func() V {
+		return get(struct{}{})
+	}, true
+}
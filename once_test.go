@@ -0,0 +1,39 @@
+package ctxcache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWithOnce(t *testing.T) {
+	var calls atomic.Int32
+	settings := func() string {
+		calls.Add(1)
+		return "dark"
+	}
+	ctx := WithOnce(context.Background(), FuncID("settings"), settings)
+	f, ok := FromContextOnce(ctx, FuncID("settings"), settings)
+	if !ok {
+		t.Fatal("once not installed")
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if f() != "dark" {
+				t.Error("wrong value")
+			}
+		}()
+	}
+	wg.Wait()
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("calls = %d, want 1", n)
+	}
+
+	if _, ok := FromContextOnce(context.Background(), FuncID("settings"), settings); ok {
+		t.Fatal("unexpected once in empty context")
+	}
+}
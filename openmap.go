@@ -0,0 +1,159 @@
+package ctxcache
+
+import (
+	"iter"
+	"math/bits"
+	"reflect"
+	"unsafe"
+)
+
+// openMap is an open-addressing hash table with linear probing, for integer
+// keys. It is guarded by the cache lock like lockedMap.
+type openMap[K comparable, V any] struct {
+	slots []openSlot[K, V]
+	// n counts the entries, used the entries and tombstones.
+	n, used int
+	// shift turns a 64-bit hash into a slot index.
+	shift uint
+	hash  func(K) uint64
+}
+
+// openSlot is empty when e is nil and tomb is false.
+type openSlot[K comparable, V any] struct {
+	key  K
+	e    *entry[K, V]
+	tomb bool
+}
+
+// intHash returns a function reading the bits of integer keys, or nil if K
+// is not an integer type.
+func intHash[K comparable]() func(K) uint64 {
+	switch reflect.TypeFor[K]().Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+	default:
+		return nil
+	}
+	var k K
+	switch unsafe.Sizeof(k) {
+	case 1:
+		return func(k K) uint64 { return uint64(*(*uint8)(unsafe.Pointer(&k))) }
+	case 2:
+		return func(k K) uint64 { return uint64(*(*uint16)(unsafe.Pointer(&k))) }
+	case 4:
+		return func(k K) uint64 { return uint64(*(*uint32)(unsafe.Pointer(&k))) }
+	default:
+		return func(k K) uint64 { return *(*uint64)(unsafe.Pointer(&k)) }
+	}
+}
+
+// index returns the first slot to probe for k, spreading consecutive keys
+// with Fibonacci hashing.
+func (m *openMap[K, V]) index(k K) int {
+	return int((m.hash(k) * 0x9e3779b97f4a7c15) >> m.shift)
+}
+
+func (m *openMap[K, V]) load(k K) (*entry[K, V], bool) {
+	if m.n == 0 {
+		return nil, false
+	}
+	mask := len(m.slots) - 1
+	for i := m.index(k); ; i = (i + 1) & mask {
+		s := &m.slots[i]
+		if s.e == nil {
+			if !s.tomb {
+				return nil, false
+			}
+			continue
+		}
+		if s.key == k {
+			return s.e, true
+		}
+	}
+}
+
+func (m *openMap[K, V]) store(k K, e *entry[K, V]) {
+	if (m.used+1)*8 > len(m.slots)*7 {
+		m.resize()
+	}
+	mask := len(m.slots) - 1
+	free := -1
+	for i := m.index(k); ; i = (i + 1) & mask {
+		s := &m.slots[i]
+		switch {
+		case s.e != nil && s.key == k:
+			s.e = e
+			return
+		case s.e == nil && s.tomb:
+			if free < 0 {
+				free = i
+			}
+		case s.e == nil:
+			if free < 0 {
+				free = i
+				m.used++
+			}
+			m.slots[free] = openSlot[K, V]{key: k, e: e}
+			m.n++
+			return
+		}
+	}
+}
+
+// resize rehashes the entries into a table large enough for twice as many,
+// dropping the tombstones.
+func (m *openMap[K, V]) resize() {
+	size := 16
+	for size*7 < (m.n+1)*2*8 {
+		size *= 2
+	}
+	old := m.slots
+	m.slots = make([]openSlot[K, V], size)
+	m.shift = uint(64 - bits.TrailingZeros(uint(size)))
+	m.n, m.used = 0, 0
+	for _, s := range old {
+		if s.e != nil {
+			m.store(s.key, s.e)
+		}
+	}
+}
+
+func (m *openMap[K, V]) delete(k K) {
+	if m.n == 0 {
+		return
+	}
+	mask := len(m.slots) - 1
+	for i := m.index(k); ; i = (i + 1) & mask {
+		s := &m.slots[i]
+		if s.e == nil {
+			if !s.tomb {
+				return
+			}
+			continue
+		}
+		if s.key == k {
+			*s = openSlot[K, V]{tomb: true}
+			m.n--
+			return
+		}
+	}
+}
+
+func (m *openMap[K, V]) clear() {
+	clear(m.slots)
+	m.n, m.used = 0, 0
+}
+
+func (m *openMap[K, V]) len() int { return m.n }
+
+func (m *openMap[K, V]) all() iter.Seq2[K, *entry[K, V]] {
+	return func(yield func(K, *entry[K, V]) bool) {
+		for _, s := range m.slots {
+			if s.e != nil && !yield(s.key, s.e) {
+				return
+			}
+		}
+	}
+}
+
+func (*openMap[K, V]) concurrent() bool { return false }
@@ -0,0 +1,51 @@
+package ctxcache
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+func TestOpenMap(t *testing.T) {
+	m := newEntryMap[int16, int](OpenAddressing).(*openMap[int16, int])
+	want := map[int16]*entry[int16, int]{}
+	r := rand.New(rand.NewPCG(1, 2))
+	for range 20000 {
+		k := int16(r.IntN(2000) - 1000)
+		switch r.IntN(3) {
+		case 0, 1:
+			e := &entry[int16, int]{key: k}
+			m.store(k, e)
+			want[k] = e
+		default:
+			m.delete(k)
+			delete(want, k)
+		}
+	}
+	if m.len() != len(want) {
+		t.Fatalf("len = %d, want %d", m.len(), len(want))
+	}
+	for k := int16(-1000); k < 1000; k++ {
+		e, ok := m.load(k)
+		if e != want[k] || ok != (want[k] != nil) {
+			t.Fatalf("load(%d) = %v, %v", k, e, ok)
+		}
+	}
+	n := 0
+	for k, e := range m.all() {
+		if want[k] != e {
+			t.Fatalf("all yields %d", k)
+		}
+		n++
+	}
+	if n != len(want) {
+		t.Fatalf("all yields %d entries, want %d", n, len(want))
+	}
+	m.clear()
+	if _, ok := m.load(1); ok || m.len() != 0 {
+		t.Fatal("entries left after clear")
+	}
+
+	if _, ok := newEntryMap[string, int](OpenAddressing).(lockedMap[string, int]); !ok {
+		t.Fatal("string keys do not fall back to LockedMap")
+	}
+}
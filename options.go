@@ -0,0 +1,87 @@
+package ctxcache
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Option configures a cache installed by WithCache.
+type Option func(*options)
+
+type options struct {
+	logger    *slog.Logger
+	observers []Observer
+	redact    any
+
+	slowThreshold time.Duration
+	slowLogger    *slog.Logger
+
+	store   any
+	backend MapKind
+
+	maxEntries int
+	maxCost    int64
+	costFn     any
+	pins       []any
+
+	releaseOnDone bool
+
+	groups []string
+
+	hotKeys int
+
+	seed      *seed
+	recording *Recording
+	replay    bool
+
+	nondeterministic bool
+
+	chaosLatency time.Duration
+	chaosRate    float64
+
+	loadQuantiles bool
+	maxErrorRate  float64
+	internKeys    bool
+	weakValues    bool
+
+	warnEntries int
+	warnFn      func(ctx context.Context, id FuncID, entries int)
+
+	maxLoads   int
+	skipNear   time.Duration
+	strictOnce bool
+	hedgeDelay time.Duration
+
+	pprofLabels  bool
+	pprofKeyHash bool
+	limiter      Limiter
+	fallback     any
+	cacheErrs    []func(error) bool
+	cooldown     time.Duration
+	clone        any
+	guard        bool
+	trackAccess  bool
+
+	shadowRate float64
+	shadow     any
+
+	sampling   bool
+	sampleRate float64
+	enabled    func(context.Context) bool
+
+	refreshInterval time.Duration
+
+	ttl           time.Duration
+	adaptiveTTL   func(time.Duration) time.Duration
+	sweepInterval time.Duration
+	maxIdle       time.Duration
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
@@ -0,0 +1,57 @@
+package ctxcache
+
+import "context"
+
+// Pair holds the two values returned by a loader, such as an entity and its
+// etag, so that both are cached together.
+type Pair[A, B any] struct {
+	V1 A
+	V2 B
+}
+
+// NewPair returns the Pair of a and b.
+func NewPair[A, B any](a A, b B) Pair[A, B] {
+	return Pair[A, B]{V1: a, V2: b}
+}
+
+// Unpack returns the values of p.
+func (p Pair[A, B]) Unpack() (A, B) {
+	return p.V1, p.V2
+}
+
+// PairFunc adapts f, returning two values, to a CacheFunc:
+//
+//	ctx = ctxcache.WithCache(ctx, "user", ctxcache.PairFunc(loadUserWithETag))
+func PairFunc[K comparable, A, B any](f func(K) (A, B)) CacheFunc[K, Pair[A, B]] {
+	return func(k K) Pair[A, B] {
+		return NewPair(f(k))
+	}
+}
+
+// PairFuncE adapts f, returning two values and an error, to a CacheFuncE.
+func PairFuncE[K comparable, A, B any](f func(context.Context, K) (A, B, error)) CacheFuncE[K, Pair[A, B]] {
+	return func(ctx context.Context, k K) (Pair[A, B], error) {
+		a, b, err := f(ctx, k)
+		return NewPair(a, b), err
+	}
+}
+
+// Unpair turns a function returning Pairs, such as the one returned by
+// FromContext for a PairFunc loader, back into one returning two values:
+//
+//	get, _ := ctxcache.FromContext(ctx, "user", ctxcache.PairFunc(loadUserWithETag))
+//	user, etag := ctxcache.Unpair(get)(id)
+func Unpair[K comparable, A, B any](f func(K) Pair[A, B]) func(K) (A, B) {
+	return func(k K) (A, B) {
+		return f(k).Unpack()
+	}
+}
+
+// UnpairE is Unpair for functions that can fail, such as the one returned
+// by FromContextE for a PairFuncE loader.
+func UnpairE[K comparable, A, B any](f func(K) (Pair[A, B], error)) func(K) (A, B, error) {
+	return func(k K) (A, B, error) {
+		p, err := f(k)
+		return p.V1, p.V2, err
+	}
+}
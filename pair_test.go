@@ -0,0 +1,44 @@
+package ctxcache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestPair(t *testing.T) {
+	loads := 0
+	user := func(id int) (string, string) {
+		loads++
+		return "user" + strconv.Itoa(id), "etag" + strconv.Itoa(id)
+	}
+	ctx := WithCache(context.Background(), FuncID("user"), PairFunc(user))
+	get, _ := FromContext(ctx, FuncID("user"), PairFunc(user))
+	f := Unpair(get)
+	for range 2 {
+		if name, etag := f(1); name != "user1" || etag != "etag1" {
+			t.Fatalf("f(1) = %q, %q", name, etag)
+		}
+	}
+	if loads != 1 {
+		t.Fatalf("loads = %d, want 1", loads)
+	}
+
+	errMissing := errors.New("missing")
+	userE := func(_ context.Context, id int) (string, int, error) {
+		if id < 0 {
+			return "", 0, errMissing
+		}
+		return "user", id, nil
+	}
+	ctx = WithCacheE(context.Background(), FuncID("userE"), PairFuncE(userE))
+	getE, _ := FromContextE(ctx, FuncID("userE"), PairFuncE(userE))
+	g := UnpairE(getE)
+	if name, version, err := g(2); name != "user" || version != 2 || err != nil {
+		t.Fatalf("g(2) = %q, %d, %v", name, version, err)
+	}
+	if _, _, err := g(-1); !errors.Is(err, errMissing) {
+		t.Fatalf("g(-1) err = %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package ctxcache
+
+import (
+	"io"
+	"time"
+)
+
+// sharedRecord is an entry of a Shared snapshot.
+type sharedRecord[K comparable, V any] struct {
+	Key    K
+	Value  V
+	Expiry time.Time
+	Delta  time.Duration
+}
+
+// SaveTo writes a snapshot of the entries of s to w, serialized with codec,
+// for LoadFrom to restore after a restart.
+func (s *Shared[K, V]) SaveTo(w io.Writer, codec Codec) error {
+	var records []sharedRecord[K, V]
+	for _, sh := range s.shards {
+		sh.lock.Lock()
+		for el := sh.ll.Back(); el != nil; el = el.Prev() {
+			e := el.Value.(*sharedEntry[K, V])
+			records = append(records, sharedRecord[K, V]{Key: e.key, Value: e.value, Expiry: e.expiry, Delta: e.delta})
+		}
+		sh.lock.Unlock()
+	}
+	data, err := codec.Marshal(records)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// LoadFrom adds the entries of a snapshot written by SaveTo with codec to s,
+// keeping their expiration and recency. Entries expired since the snapshot
+// are skipped, and entries are given the TTL of s if it expires sooner than
+// their own expiration, or if they had none.
+func (s *Shared[K, V]) LoadFrom(r io.Reader, codec Codec) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	var records []sharedRecord[K, V]
+	if err := codec.Unmarshal(data, &records); err != nil {
+		return err
+	}
+	s.gen.Add(1)
+	now := s.now()
+	for _, rec := range records {
+		if !rec.Expiry.IsZero() && !now.Before(rec.Expiry) {
+			continue
+		}
+		sh := s.shard(rec.Key)
+		sh.lock.Lock()
+		s.set(sh, rec.Key, rec.Value, rec.Delta)
+		// Keep the earlier of the snapshot and TTL expiries.
+		if e := sh.items[rec.Key].Value.(*sharedEntry[K, V]); !rec.Expiry.IsZero() && (e.expiry.IsZero() || rec.Expiry.Before(e.expiry)) {
+			e.expiry = rec.Expiry
+		}
+		sh.lock.Unlock()
+	}
+	return nil
+}
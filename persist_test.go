@@ -0,0 +1,62 @@
+package ctxcache
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSharedSaveLoad(t *testing.T) {
+	for _, codec := range []Codec{JSON, Gob} {
+		now := time.Now()
+		src := NewShared[string, int](0, ExpireAfter(time.Minute))
+		src.now = func() time.Time { return now }
+		src.Set(context.Background(), "a", 1)
+		src.Set(context.Background(), "b", 2)
+		var buf bytes.Buffer
+		if err := src.SaveTo(&buf, codec); err != nil {
+			t.Fatal(err)
+		}
+
+		dst := NewShared[string, int](0)
+		dst.now = func() time.Time { return now.Add(time.Second) }
+		if err := dst.LoadFrom(&buf, codec); err != nil {
+			t.Fatal(err)
+		}
+		if v, ok, _ := dst.Get(context.Background(), "b"); !ok || v != 2 {
+			t.Fatalf("b = %d, %v after restore", v, ok)
+		}
+		if got := dst.shards[0].ll.Front().Value.(*sharedEntry[string, int]).key; got != "b" {
+			t.Fatalf("most recent entry = %q", got)
+		}
+
+		buf.Reset()
+		src.SaveTo(&buf, codec)
+		late := NewShared[string, int](0)
+		late.now = func() time.Time { return now.Add(time.Hour) }
+		late.LoadFrom(&buf, codec)
+		if late.Len() != 0 {
+			t.Fatalf("restored %d expired entries", late.Len())
+		}
+	}
+}
+
+func TestSharedLoadKeepsTTL(t *testing.T) {
+	now := time.Now()
+	src := NewShared[string, int](0)
+	src.Set(context.Background(), "a", 1)
+	var buf bytes.Buffer
+	if err := src.SaveTo(&buf, JSON); err != nil {
+		t.Fatal(err)
+	}
+	dst := NewShared[string, int](0, ExpireAfter(time.Minute))
+	dst.now = func() time.Time { return now }
+	if err := dst.LoadFrom(&buf, JSON); err != nil {
+		t.Fatal(err)
+	}
+	dst.now = func() time.Time { return now.Add(time.Hour) }
+	if _, ok, _ := dst.Get(context.Background(), "a"); ok {
+		t.Fatal("entry restored without expiry outlived the TTL")
+	}
+}
@@ -0,0 +1,33 @@
+package ctxcache
+
+import (
+	"context"
+	"hash/maphash"
+	"runtime/pprof"
+	"strconv"
+)
+
+// ProfileLabels runs loader calls under the pprof label func_id, so CPU
+// profiles attribute loader time to caches. With keyHash, a key_hash label
+// holding a hash of the key is added, telling keys apart without exposing
+// them.
+func ProfileLabels(keyHash bool) Option {
+	return func(o *options) {
+		o.pprofLabels = true
+		o.pprofKeyHash = keyHash
+	}
+}
+
+func (c *cache[K, V]) labeled(load CacheFuncE[K, V]) CacheFuncE[K, V] {
+	return func(ctx context.Context, k K) (v V, err error) {
+		labels := []string{"func_id", string(c.id)}
+		if c.opts.pprofKeyHash {
+			h := maphash.String(hashSeed, c.keyString(k))
+			labels = append(labels, "key_hash", strconv.FormatUint(h, 16))
+		}
+		pprof.Do(ctx, pprof.Labels(labels...), func(ctx context.Context) {
+			v, err = load(ctx, k)
+		})
+		return v, err
+	}
+}
@@ -0,0 +1,22 @@
+package ctxcache
+
+import (
+	"context"
+	"runtime/pprof"
+	"testing"
+)
+
+func TestProfileLabels(t *testing.T) {
+	var id, hash string
+	load := func(ctx context.Context, n int) (int, error) {
+		id, _ = pprof.Label(ctx, "func_id")
+		hash, _ = pprof.Label(ctx, "key_hash")
+		return n, nil
+	}
+	ctx := WithCacheE(context.Background(), FuncID("pprof"), load, ProfileLabels(true))
+	f, _ := FromContextE(ctx, FuncID("pprof"), load)
+	f(1)
+	if id != "pprof" || hash == "" {
+		t.Fatalf("labels func_id=%q key_hash=%q", id, hash)
+	}
+}
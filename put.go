@@ -0,0 +1,46 @@
+package ctxcache
+
+import "context"
+
+// PutThrough writes value to the backing store with sink, then to the cache
+// installed in ctx under ctxKey and to its Store, if any. If sink fails the
+// cache is left unchanged and the error is returned, so readers never see a
+// value the backing store rejected. Entries derived from key with DependsOn
+// are invalidated. Without a cache in ctx only sink is called.
+//
+// The Store is written unconditionally, as value is newer than any load in
+// flight. If that write fails, key is deleted from the Store instead, and if
+// the deletion fails too, its error is returned, as the Store may keep
+// serving the old value.
+func PutThrough[K comparable, V any](ctx context.Context, ctxKey FuncID, key K, value V, sink func(K, V) error) error {
+	if err := sink(key, value); err != nil {
+		return err
+	}
+	c, ok := cacheValue(ctx, ctxKey).(*cache[K, V])
+	if !ok {
+		return nil
+	}
+	c.invalidate(key)
+	c.set(ctx, key, value, nil, 0)
+	return c.storePut(ctx, key, value)
+}
+
+// storePut writes v under k in the store, deleting k if that fails. It
+// returns the error of the deletion.
+func (c *cache[K, V]) storePut(ctx context.Context, k K, v V) error {
+	if c.store == nil {
+		return nil
+	}
+	err := c.store.Set(ctx, k, v)
+	if err == nil {
+		c.storeErr.Store(&err)
+		return nil
+	}
+	c.logStoreError(ctx, k, err)
+	derr := c.store.Delete(ctx, k)
+	if derr != nil {
+		c.logStoreError(ctx, k, derr)
+	}
+	c.storeErr.Store(&err)
+	return derr
+}
@@ -0,0 +1,97 @@
+package ctxcache
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestPutThrough(t *testing.T) {
+	db := map[int]string{1: "one"}
+	get := func(_ context.Context, k int) (string, error) {
+		return db[k], nil
+	}
+	sink := func(k int, v string) error {
+		if v == "" {
+			return errors.New("empty value")
+		}
+		db[k] = v
+		return nil
+	}
+	shared := NewShared[int, string](10)
+	ctx := WithCacheE(context.Background(), FuncID("get"), get, WithStore[int, string](shared))
+	f, _ := FromContextE(ctx, FuncID("get"), get)
+	if v, _ := f(1); v != "one" {
+		t.Fatalf("f(1) = %q", v)
+	}
+
+	if err := PutThrough(ctx, FuncID("get"), 1, "uno", sink); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := f(1); v != "uno" || db[1] != "uno" {
+		t.Fatalf("f(1) = %q, db[1] = %q after put", v, db[1])
+	}
+	if v, ok, _ := shared.Get(ctx, 1); !ok || v != "uno" {
+		t.Fatalf("shared store holds %q, %v", v, ok)
+	}
+
+	if err := PutThrough(ctx, FuncID("get"), 1, "", sink); err == nil {
+		t.Fatal("sink error not returned")
+	}
+	if v, _ := f(1); v != "uno" {
+		t.Fatalf("f(1) = %q after failed put", v)
+	}
+
+	if err := PutThrough(context.Background(), FuncID("get"), 2, "two", sink); err != nil || db[2] != "two" {
+		t.Fatalf("put without cache: %v, db[2] = %q", err, db[2])
+	}
+}
+
+// rejectingStore is a Shared whose writes fail.
+type rejectingStore struct {
+	*Shared[int, string]
+	deleteErr error
+}
+
+func (s rejectingStore) Set(context.Context, int, string) error { return errors.New("read only") }
+
+func (s rejectingStore) Delete(ctx context.Context, k int) error {
+	if s.deleteErr != nil {
+		return s.deleteErr
+	}
+	return s.Shared.Delete(ctx, k)
+}
+
+func TestPutThroughStore(t *testing.T) {
+	get := func(_ context.Context, k int) (string, error) { return "old", nil }
+	sink := func(int, string) error { return nil }
+	shared := NewShared[int, string](10)
+	ctx := WithCacheE(context.Background(), FuncID("get"), get, WithStore[int, string](shared))
+	f, _ := FromContextE(ctx, FuncID("get"), get)
+	f(1)
+	token := shared.token()
+	shared.Delete(ctx, 2)
+	if shared.token() == token {
+		t.Fatal("Delete did not bump the store token")
+	}
+	if err := PutThrough(ctx, FuncID("get"), 1, "new", sink); err != nil {
+		t.Fatal(err)
+	}
+	if v, _, _ := shared.Get(ctx, 1); v != "new" {
+		t.Fatalf("store holds %q after put", v)
+	}
+
+	store := rejectingStore{Shared: shared}
+	ctx = WithCacheE(context.Background(), FuncID("get"), get, WithStore[int, string](store))
+	if err := PutThrough(ctx, FuncID("get"), 1, "newer", sink); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := shared.Get(ctx, 1); ok {
+		t.Fatal("stale value kept in the store after a failed write")
+	}
+	store.deleteErr = errDown
+	ctx = WithCacheE(context.Background(), FuncID("get"), get, WithStore[int, string](store))
+	if err := PutThrough(ctx, FuncID("get"), 1, "newest", sink); !errors.Is(err, errDown) {
+		t.Fatalf("err = %v, want the deletion error", err)
+	}
+}
@@ -0,0 +1,122 @@
+package ctxcache
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+// LoadQuantiles estimates the median, 90th and 99th percentile of load
+// durations, reported in Stats per cache and in ProcessStats per FuncID.
+// The estimates use the P² streaming algorithm, in constant memory.
+func LoadQuantiles() Option {
+	return func(o *options) {
+		o.loadQuantiles = true
+	}
+}
+
+// quantiles estimates the p50, p90 and p99 of load durations.
+type quantiles struct {
+	lock sync.Mutex
+	est  [3]p2
+}
+
+func newQuantiles() *quantiles {
+	return &quantiles{est: [3]p2{{p: 0.5}, {p: 0.9}, {p: 0.99}}}
+}
+
+func (q *quantiles) add(d time.Duration) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	for i := range q.est {
+		q.est[i].add(float64(d))
+	}
+}
+
+func (q *quantiles) values() (p50, p90, p99 time.Duration) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return time.Duration(q.est[0].value()), time.Duration(q.est[1].value()), time.Duration(q.est[2].value())
+}
+
+// p2 estimates the quantile p of a stream with the P² algorithm of Jain and
+// Chlamtac, tracking five markers: the minimum, p/2, p, (1+p)/2 and the
+// maximum.
+type p2 struct {
+	p     float64
+	count int
+	// heights, positions, desired positions and their increments.
+	q, n, np, dn [5]float64
+}
+
+func (e *p2) add(x float64) {
+	if e.count < 5 {
+		e.q[e.count] = x
+		e.count++
+		if e.count == 5 {
+			slices.Sort(e.q[:])
+			p := e.p
+			e.n = [5]float64{1, 2, 3, 4, 5}
+			e.np = [5]float64{1, 1 + 2*p, 1 + 4*p, 3 + 2*p, 5}
+			e.dn = [5]float64{0, p / 2, p, (1 + p) / 2, 1}
+		}
+		return
+	}
+	e.count++
+	k := 0
+	switch {
+	case x < e.q[0]:
+		e.q[0] = x
+	case x >= e.q[4]:
+		e.q[4] = x
+		k = 3
+	default:
+		for k < 3 && x >= e.q[k+1] {
+			k++
+		}
+	}
+	for i := k + 1; i < 5; i++ {
+		e.n[i]++
+	}
+	for i := range e.np {
+		e.np[i] += e.dn[i]
+	}
+	for i := 1; i <= 3; i++ {
+		d := e.np[i] - e.n[i]
+		if (d >= 1 && e.n[i+1]-e.n[i] > 1) || (d <= -1 && e.n[i-1]-e.n[i] < -1) {
+			s := math.Copysign(1, d)
+			h := e.parabolic(i, s)
+			if h <= e.q[i-1] || h >= e.q[i+1] {
+				h = e.linear(i, s)
+			}
+			e.q[i] = h
+			e.n[i] += s
+		}
+	}
+}
+
+func (e *p2) parabolic(i int, d float64) float64 {
+	return e.q[i] + d/(e.n[i+1]-e.n[i-1])*
+		((e.n[i]-e.n[i-1]+d)*(e.q[i+1]-e.q[i])/(e.n[i+1]-e.n[i])+
+			(e.n[i+1]-e.n[i]-d)*(e.q[i]-e.q[i-1])/(e.n[i]-e.n[i-1]))
+}
+
+func (e *p2) linear(i int, d float64) float64 {
+	j := i + int(d)
+	return e.q[i] + d*(e.q[j]-e.q[i])/(e.n[j]-e.n[i])
+}
+
+// value returns the estimate, exact while fewer than five samples were
+// added.
+func (e *p2) value() float64 {
+	if e.count == 0 {
+		return 0
+	}
+	if e.count < 5 {
+		s := slices.Clone(e.q[:e.count])
+		slices.Sort(s)
+		return s[int(math.Round(e.p*float64(e.count-1)))]
+	}
+	return e.q[2]
+}
@@ -0,0 +1,53 @@
+package ctxcache
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestP2(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	samples := r.Perm(10000)
+	for _, p := range []float64{0.5, 0.9, 0.99} {
+		e := p2{p: p}
+		for _, x := range samples {
+			e.add(float64(x))
+		}
+		want := p * 10000
+		if got := e.value(); math.Abs(got-want) > 100 {
+			t.Errorf("p%v = %v, want about %v", p*100, got, want)
+		}
+	}
+
+	e := p2{p: 0.5}
+	for _, x := range []float64{3, 1, 2} {
+		e.add(x)
+	}
+	if e.value() != 2 {
+		t.Fatalf("median of 3 samples = %v", e.value())
+	}
+}
+
+func TestLoadQuantiles(t *testing.T) {
+	inc := func(n int) int { return n + 1 }
+	ctx := WithCache(context.Background(), FuncID("quantiles"), inc, LoadQuantiles())
+	ctx = WithCache(ctx, FuncID("inc"), inc)
+	for _, id := range []FuncID{"quantiles", "inc"} {
+		f, _ := FromContext(ctx, id, inc)
+		for n := range 10 {
+			f(n)
+		}
+	}
+	s, _ := GetStats(ctx, FuncID("quantiles"))
+	if s.LoadP50 <= 0 || s.LoadP50 > s.LoadP90 || s.LoadP90 > s.LoadP99 {
+		t.Fatalf("quantiles = %v, %v, %v", s.LoadP50, s.LoadP90, s.LoadP99)
+	}
+	if ProcessStats()["quantiles"].LoadP99 <= 0 {
+		t.Fatal("process quantiles not tracked")
+	}
+	if s, _ := GetStats(ctx, FuncID("inc")); s.LoadP50 != 0 {
+		t.Fatal("quantiles tracked without LoadQuantiles")
+	}
+}
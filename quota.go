@@ -0,0 +1,43 @@
+package ctxcache
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrMaxLoads is returned by caches installed with MaxLoads once their
+// quota of loads is used up.
+var ErrMaxLoads = errors.New("ctxcache: max loads exceeded")
+
+// MaxLoads limits the cache to n loader calls. Further misses return
+// ErrMaxLoads from FromContextE, and the zero value from FromContext.
+func MaxLoads(n int) Option {
+	return func(o *options) {
+		o.maxLoads = n
+	}
+}
+
+// Limiter throttles loads. *rate.Limiter from golang.org/x/time/rate
+// implements it.
+type Limiter interface {
+	Wait(ctx context.Context) error
+}
+
+// RateLimit makes every loader call wait for limiter first, throttling the
+// misses of the cache. Loads whose context is done while waiting fail with
+// the error of limiter.
+func RateLimit(limiter Limiter) Option {
+	return func(o *options) {
+		o.limiter = limiter
+	}
+}
+
+func (c *cache[K, V]) reserveLoad(ctx context.Context) error {
+	if c.opts.maxLoads > 0 && c.reserved.Add(1) > int64(c.opts.maxLoads) {
+		return ErrMaxLoads
+	}
+	if c.opts.limiter != nil {
+		return c.opts.limiter.Wait(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package ctxcache
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMaxLoads(t *testing.T) {
+	double := func(_ context.Context, n int) (int, error) { return n * 2, nil }
+	ctx := WithCacheE(context.Background(), FuncID("double"), double, MaxLoads(2))
+	f, _ := FromContextE(ctx, FuncID("double"), double)
+	for _, n := range []int{1, 2, 1, 2} {
+		if v, err := f(n); err != nil || v != n*2 {
+			t.Fatalf("f(%d) = %d, %v", n, v, err)
+		}
+	}
+	if _, err := f(3); !errors.Is(err, ErrMaxLoads) {
+		t.Fatalf("err = %v, want ErrMaxLoads", err)
+	}
+
+	g, _ := FromContext[int, int](ctx, FuncID("double"), nil)
+	if g(1) != 2 || g(4) != 0 {
+		t.Fatal("plain API should return the zero value over quota")
+	}
+}
+
+type countingLimiter struct {
+	waits int
+	err   error
+}
+
+func (l *countingLimiter) Wait(context.Context) error {
+	l.waits++
+	return l.err
+}
+
+func TestRateLimit(t *testing.T) {
+	double := func(_ context.Context, n int) (int, error) { return n * 2, nil }
+	limiter := &countingLimiter{}
+	ctx := WithCacheE(context.Background(), FuncID("double"), double, RateLimit(limiter))
+	f, _ := FromContextE(ctx, FuncID("double"), double)
+	f(1)
+	f(1)
+	f(2)
+	if limiter.waits != 2 {
+		t.Fatalf("waits = %d, want 2", limiter.waits)
+	}
+	limiter.err = context.DeadlineExceeded
+	if _, err := f(3); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v", err)
+	}
+}
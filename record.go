@@ -0,0 +1,110 @@
+package ctxcache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// ErrNotRecorded is returned in replay mode for keys missing from the
+// recording.
+var ErrNotRecorded = errors.New("ctxcache: key not recorded")
+
+// Recording holds the values produced by loaders, by FuncID and by key
+// formatted with fmt.Sprint, for hermetic tests. It is safe for concurrent
+// use.
+type Recording struct {
+	lock   sync.Mutex
+	values map[FuncID]map[string]json.RawMessage
+}
+
+// NewRecording returns an empty Recording.
+func NewRecording() *Recording {
+	return &Recording{values: make(map[FuncID]map[string]json.RawMessage)}
+}
+
+// ReadRecording reads a recording written by WriteFile.
+func ReadRecording(name string) (*Recording, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	r := NewRecording()
+	if err := json.Unmarshal(data, &r.values); err != nil {
+		return nil, fmt.Errorf("ctxcache: reading recording %s: %w", name, err)
+	}
+	return r, nil
+}
+
+// WriteFile writes the recording as JSON to the file name.
+func (r *Recording) WriteFile(name string) error {
+	r.lock.Lock()
+	data, err := json.MarshalIndent(r.values, "", "  ")
+	r.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, data, 0o644)
+}
+
+// Record adds every value returned by the loader to r. Values must be
+// JSON-serializable; errors are not recorded.
+func Record(r *Recording) Option {
+	return func(o *options) {
+		o.recording = r
+		o.replay = false
+	}
+}
+
+// Replay serves loads from r instead of calling the loader. Keys missing
+// from r fail with ErrNotRecorded.
+func Replay(r *Recording) Option {
+	return func(o *options) {
+		o.recording = r
+		o.replay = true
+	}
+}
+
+func (r *Recording) put(id FuncID, key string, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.values[id] == nil {
+		r.values[id] = make(map[string]json.RawMessage)
+	}
+	r.values[id][key] = data
+}
+
+func (r *Recording) get(id FuncID, key string, v any) error {
+	r.lock.Lock()
+	data, ok := r.values[id][key]
+	r.lock.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %s(%s)", ErrNotRecorded, id, key)
+	}
+	return json.Unmarshal(data, v)
+}
+
+func (c *cache[K, V]) recorded(load CacheFuncE[K, V]) CacheFuncE[K, V] {
+	r := c.opts.recording
+	if c.opts.replay {
+		return func(_ context.Context, k K) (V, error) {
+			var v V
+			err := r.get(c.id, c.recordKey(k), &v)
+			return v, err
+		}
+	}
+	return func(ctx context.Context, k K) (V, error) {
+		v, err := load(ctx, k)
+		if err == nil {
+			r.put(c.id, c.recordKey(k), v)
+		}
+		return v, err
+	}
+}
@@ -0,0 +1,45 @@
+package ctxcache
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecordReplay(t *testing.T) {
+	type user struct {
+		Name string
+		Age  int
+	}
+	loads := 0
+	get := func(_ context.Context, id int) (user, error) {
+		loads++
+		return user{Name: "user", Age: id}, nil
+	}
+	rec := NewRecording()
+	ctx := WithCacheE(context.Background(), FuncID("user"), get, Record(rec))
+	f, _ := FromContextE(ctx, FuncID("user"), get)
+	f(1)
+	f(2)
+	name := filepath.Join(t.TempDir(), "user.json")
+	if err := rec.WriteFile(name); err != nil {
+		t.Fatal(err)
+	}
+
+	replay, err := ReadRecording(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx = WithCacheE(context.Background(), FuncID("user"), get, Replay(replay))
+	g, _ := FromContextE(ctx, FuncID("user"), get)
+	if u, err := g(2); err != nil || u != (user{Name: "user", Age: 2}) {
+		t.Fatalf("g(2) = %v, %v", u, err)
+	}
+	if _, err := g(3); !errors.Is(err, ErrNotRecorded) {
+		t.Fatalf("g(3) err = %v", err)
+	}
+	if loads != 2 {
+		t.Fatalf("loads = %d, want the loader not called in replay", loads)
+	}
+}
@@ -0,0 +1,75 @@
+package ctxcache
+
+import (
+	"container/heap"
+	"context"
+	"time"
+)
+
+// RefreshAhead reloads, every interval, the entries read during the last
+// interval, keeping hot entries fresh in long-lived contexts. Reloads go
+// through the same quotas and wrappers as misses, do not count as reads, and
+// keep the entry if they fail. The worker stops when the context the cache
+// is installed in is done or on Release.
+func RefreshAhead(interval time.Duration) Option {
+	return func(o *options) {
+		o.refreshInterval = interval
+	}
+}
+
+// read is an entry due for a refresh.
+type read[K comparable] struct {
+	key      K
+	version  string
+	accessed int64
+}
+
+func (c *cache[K, V]) refreshAhead(ctx context.Context, now time.Time) {
+	since := now.Add(-c.opts.refreshInterval).UnixNano()
+	var reads []read[K]
+	c.lock.RLock()
+	for k, e := range c.data.all() {
+		if e.x == nil {
+			continue
+		}
+		if accessed := e.x.accessed.Load(); accessed >= since {
+			reads = append(reads, read[K]{key: k, version: e.x.version, accessed: accessed})
+		}
+	}
+	c.lock.RUnlock()
+
+	for _, r := range reads {
+		if err := c.reserveLoad(ctx); err != nil {
+			return
+		}
+		ctx := ctx
+		if r.version != "" {
+			ctx = WithVersion(ctx, r.version)
+		}
+		token := c.storeToken()
+		v, d, err := c.call(ctx, r.key)
+		if err != nil {
+			continue
+		}
+		c.refresh(ctx, r, v, d)
+		c.storeSet(ctx, r.key, v, token, d)
+	}
+}
+
+// refresh stores v under r.key like set, keeping the time it was last read.
+func (c *cache[K, V]) refresh(ctx context.Context, r read[K], v V, loadTime time.Duration) {
+	c.lock.Lock()
+	evicted := c.insert(r.key, v, nil, r.version, loadTime)
+	if e, ok := c.data.load(r.key); ok {
+		x := e.extra()
+		x.accessed.Store(r.accessed)
+		if x.index >= 0 {
+			x.due = c.deadline(e)
+			heap.Fix(&c.expiries, x.index)
+		}
+	}
+	n := c.data.len()
+	c.lock.Unlock()
+	c.evicted(ctx, evicted)
+	c.checkGrowth(ctx, n)
+}
@@ -0,0 +1,72 @@
+package ctxcache
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRefreshAhead(t *testing.T) {
+	var version atomic.Int32
+	load := func(int) int32 { return version.Load() }
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctx = WithCache(ctx, FuncID("version"), load, RefreshAhead(5*time.Millisecond))
+	f, _ := FromContext(ctx, FuncID("version"), load)
+
+	if f(1) != 0 {
+		t.Fatal("unexpected initial value")
+	}
+	version.Store(1)
+	deadline := time.Now().Add(time.Second)
+	for f(1) != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("entry not refreshed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	Release(ctx)
+	f(1)
+	loads := func() uint64 { s, _ := GetStats(ctx, FuncID("version")); return s.Loads }
+	before := loads()
+	time.Sleep(20 * time.Millisecond)
+	if loads() != before {
+		t.Fatal("refresh worker still running after Release")
+	}
+}
+
+func TestRefreshAheadKeepsReads(t *testing.T) {
+	load := func(n int) int { return n }
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctx = WithCache(ctx, FuncID("identity"), load, RefreshAhead(5*time.Millisecond))
+	loads := func() uint64 { s, _ := GetStats(ctx, FuncID("identity")); return s.Loads }
+
+	f, _ := FromContext(WithVersion(ctx, "v1"), FuncID("identity"), load)
+	f(1)
+	time.Sleep(time.Millisecond)
+	f(1)
+	deadline := time.Now().Add(time.Second)
+	for loads() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("entry not refreshed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	f(1)
+	if n := loads(); n != 2 {
+		t.Fatalf("loads = %d, want the refreshed entry kept under its version", n)
+	}
+	cancel()
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	ctx = WithCache(ctx, FuncID("identity"), load, RefreshAhead(5*time.Millisecond))
+	f, _ = FromContext(ctx, FuncID("identity"), load)
+	f(1)
+	time.Sleep(40 * time.Millisecond)
+	if n := loads(); n > 3 {
+		t.Fatalf("loads = %d, entry read once refreshed forever", n)
+	}
+}
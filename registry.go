@@ -0,0 +1,154 @@
+package ctxcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// anyCache is the type-erased view of a cache used for operations spanning
+// every cache installed in a context.
+type anyCache interface {
+	funcID() FuncID
+	release()
+	stopWorkers()
+	stats() Stats
+	dump() cacheDump
+	invalidate(key any) bool
+	inGroup(group string) bool
+	freeze(missErr error)
+	subscribe(ch chan Event)
+	unsubscribe(ch chan Event)
+	report() CacheReport
+	hotKeys() []KeyCount
+	estimatedBytes() int64
+	space(ns string) anyCache
+	partitions(fn func(anyCache))
+	addDependent(key any, l loading) bool
+	addParent(key any, p loading)
+	dropDependent(key any, l loading)
+	health() error
+}
+
+type registryKey struct{}
+
+// registration links the caches installed in a context chain, newest first.
+type registration struct {
+	cache anyCache
+	prev  *registration
+}
+
+// ErrTooManyCaches reports a cache installed beyond the limit of
+// LimitCaches.
+var ErrTooManyCaches = errors.New("ctxcache: too many caches in context")
+
+type limitKey struct{}
+
+type cacheLimit struct {
+	n        int
+	onExceed func(ctx context.Context, err error)
+}
+
+// LimitCaches caps at n the number of distinct FuncIDs installed in ctx
+// and the contexts derived from it, guarding against code installing
+// caches in a loop. Installing a cache over the limit calls onExceed with
+// an error wrapping ErrTooManyCaches; the cache is still installed. With a
+// nil onExceed, the installation panics with that error instead.
+func LimitCaches(ctx context.Context, n int, onExceed func(ctx context.Context, err error)) context.Context {
+	return context.WithValue(ctx, limitKey{}, &cacheLimit{n: n, onExceed: onExceed})
+}
+
+func (l *cacheLimit) check(ctx context.Context, id FuncID) {
+	ids := map[FuncID]bool{id: true}
+	visibleCaches(ctx, func(c anyCache) {
+		ids[c.funcID()] = true
+	})
+	if len(ids) <= l.n {
+		return
+	}
+	err := fmt.Errorf("%w: installing %q makes %d, limit is %d", ErrTooManyCaches, id, len(ids), l.n)
+	if l.onExceed == nil {
+		panic(err)
+	}
+	l.onExceed(ctx, err)
+}
+
+func register(ctx context.Context, c anyCache) context.Context {
+	if l, ok := ctx.Value(limitKey{}).(*cacheLimit); ok {
+		l.check(ctx, c.funcID())
+	}
+	prev, _ := ctx.Value(registryKey{}).(*registration)
+	return context.WithValue(ctx, registryKey{}, &registration{cache: c, prev: prev})
+}
+
+// visibleCaches calls fn for every cache reachable from ctx, skipping the
+// ones shadowed by a newer cache with the same FuncID.
+func visibleCaches(ctx context.Context, fn func(anyCache)) {
+	seen := make(map[FuncID]bool)
+	for r, _ := ctx.Value(registryKey{}).(*registration); r != nil; r = r.prev {
+		id := r.cache.funcID()
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		fn(r.cache)
+	}
+}
+
+func allCaches(ctx context.Context, fn func(anyCache)) {
+	for r, _ := ctx.Value(registryKey{}).(*registration); r != nil; r = r.prev {
+		fn(r.cache)
+	}
+}
+
+// Release drops the entries of every cache installed in ctx so their memory
+// can be reclaimed before the context ends, and stops their background
+// workers. The caches stay usable and load again on the next call.
+func Release(ctx context.Context) {
+	allCaches(ctx, func(c anyCache) {
+		c.stopWorkers()
+		c.release()
+	})
+}
+
+func (c *cache[K, V]) funcID() FuncID {
+	return c.id
+}
+
+// ReleaseOnDone releases the cache once the context it is installed in is
+// done, and stops caching further loads. Background workers stop with the
+// context regardless of this option.
+func ReleaseOnDone() Option {
+	return func(o *options) {
+		o.releaseOnDone = true
+	}
+}
+
+func (c *cache[K, V]) release() {
+	c.eachSpace((*cache[K, V]).release)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.clear()
+}
+
+func (c *cache[K, V]) close() {
+	c.eachSpace((*cache[K, V]).close)
+	c.stopWorkers()
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.closed = true
+	c.clear()
+}
+
+// clear drops all entries. c.lock must be held for writing.
+func (c *cache[K, V]) clear() {
+	c.live.add(-int64(c.data.len()))
+	c.data.clear()
+	c.expiries = nil
+	c.unlinkAll()
+	c.handles = nil
+	if c.lru != nil {
+		c.lru.Init()
+		c.cost = 0
+	}
+}
@@ -0,0 +1,83 @@
+package ctxcache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRelease(t *testing.T) {
+	loads := 0
+	inc := func(n int) int {
+		loads++
+		return n + 1
+	}
+	ctx := WithCache(context.Background(), FuncID("inc"), inc)
+	ctx = WithCache(ctx, FuncID("inc2"), inc)
+	f, _ := FromContext(ctx, FuncID("inc"), inc)
+	g, _ := FromContext(ctx, FuncID("inc2"), inc)
+	f(1)
+	g(1)
+	Release(ctx)
+	f(1)
+	g(1)
+	if loads != 4 {
+		t.Fatalf("loads = %d, want 4", loads)
+	}
+}
+
+func TestReleaseOnDone(t *testing.T) {
+	loads := 0
+	inc := func(n int) int {
+		loads++
+		return n + 1
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = WithCache(ctx, FuncID("inc"), inc, ReleaseOnDone())
+	f, _ := FromContext(ctx, FuncID("inc"), inc)
+	f(1)
+	c := ctx.Value(funcKey{"inc"}).(*cache[int, int])
+
+	cancel()
+	for i := 0; i < 100; i++ {
+		c.lock.RLock()
+		closed := c.closed
+		c.lock.RUnlock()
+		if closed {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	f(1)
+	f(1)
+	if loads != 3 {
+		t.Fatalf("loads = %d, want 3", loads)
+	}
+}
+
+func TestLimitCaches(t *testing.T) {
+	load := func(n int) int { return n }
+	var errs []error
+	ctx := LimitCaches(context.Background(), 2, func(_ context.Context, err error) {
+		errs = append(errs, err)
+	})
+	ctx = WithCache(ctx, FuncID("a"), load)
+	ctx = WithCache(ctx, FuncID("b"), load)
+	ctx = WithCache(ctx, FuncID("a"), load)
+	if len(errs) != 0 {
+		t.Fatalf("errors under the limit: %v", errs)
+	}
+	WithCache(ctx, FuncID("c"), load)
+	if len(errs) != 1 || !errors.Is(errs[0], ErrTooManyCaches) {
+		t.Fatalf("errors = %v", errs)
+	}
+
+	defer func() {
+		if err, _ := recover().(error); !errors.Is(err, ErrTooManyCaches) {
+			t.Fatalf("recovered %v", err)
+		}
+	}()
+	ctx = LimitCaches(context.Background(), 0, nil)
+	WithCache(ctx, FuncID("a"), load)
+}
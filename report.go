@@ -0,0 +1,109 @@
+package ctxcache
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// reportSlowest is the number of slowest loads kept per cache for Report.
+const reportSlowest = 5
+
+// Report summarizes the caches installed in a context, typically logged at
+// the end of a request to find caching opportunities.
+type Report struct {
+	Caches []CacheReport `json:"caches"`
+}
+
+// CacheReport summarizes a single cache.
+type CacheReport struct {
+	FuncID FuncID `json:"func_id"`
+	// Namespace is the namespace of the partitions of a cache.
+	Namespace string        `json:"namespace,omitempty"`
+	Hits      uint64        `json:"hits"`
+	Misses    uint64        `json:"misses"`
+	Loads     uint64        `json:"loads"`
+	LoadTime  time.Duration `json:"load_time"`
+	// Slowest are the slowest loads, slowest first.
+	Slowest []KeyLoad `json:"slowest"`
+}
+
+// KeyLoad is a load of a key. Key is formatted like in logs.
+type KeyLoad struct {
+	Key      string        `json:"key"`
+	Duration time.Duration `json:"duration"`
+}
+
+// GetReport returns the Report of the caches installed in ctx and of their
+// Namespace partitions.
+func GetReport(ctx context.Context) Report {
+	var r Report
+	visibleCaches(ctx, withPartitions(func(c anyCache) {
+		r.Caches = append(r.Caches, c.report())
+	}))
+	return r
+}
+
+// LogValue groups the caches by FuncID, and namespace for partitions, so a
+// Report can be attached to an access log line with
+// slog.Any("ctxcache", report).
+func (r Report) LogValue() slog.Value {
+	attrs := make([]slog.Attr, 0, len(r.Caches))
+	for _, c := range r.Caches {
+		name := string(c.FuncID)
+		if c.Namespace != "" {
+			name += "/" + c.Namespace
+		}
+		attrs = append(attrs, slog.Group(name,
+			slog.Uint64("hits", c.Hits),
+			slog.Uint64("misses", c.Misses),
+			slog.Uint64("loads", c.Loads),
+			slog.Duration("load_time", c.LoadTime),
+		))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+func (c *cache[K, V]) report() CacheReport {
+	s := c.counters.snapshot()
+	return CacheReport{
+		FuncID:    c.id,
+		Namespace: c.ns,
+		Hits:      s.Hits,
+		Misses:    s.Misses,
+		Loads:     s.Loads,
+		LoadTime:  s.LoadTime,
+		Slowest:   c.slowest.list(),
+	}
+}
+
+// slowLoads keeps the slowest loads of a cache.
+type slowLoads struct {
+	lock  sync.Mutex
+	loads []KeyLoad
+}
+
+// record adds a load of d, calling key only if it is among the slowest.
+func (s *slowLoads) record(d time.Duration, key func() string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if len(s.loads) == reportSlowest && d <= s.loads[len(s.loads)-1].Duration {
+		return
+	}
+	i := len(s.loads)
+	for i > 0 && s.loads[i-1].Duration < d {
+		i--
+	}
+	if len(s.loads) < reportSlowest {
+		s.loads = append(s.loads, KeyLoad{})
+	}
+	copy(s.loads[i+1:], s.loads[i:])
+	s.loads[i] = KeyLoad{Key: key(), Duration: d}
+}
+
+func (s *slowLoads) list() []KeyLoad {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]KeyLoad(nil), s.loads...)
+}
@@ -0,0 +1,56 @@
+package ctxcache
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetReport(t *testing.T) {
+	load := func(n int) int {
+		time.Sleep(time.Duration(n) * time.Millisecond)
+		return n
+	}
+	ctx := WithCache(context.Background(), FuncID("report"), load)
+	f, _ := FromContext(ctx, FuncID("report"), load)
+	for _, n := range []int{1, 3, 2, 3, 0, 4, 5, 6} {
+		f(n)
+	}
+	r := GetReport(ctx)
+	if len(r.Caches) != 1 {
+		t.Fatalf("report = %+v", r)
+	}
+	c := r.Caches[0]
+	if c.Hits != 1 || c.Loads != 7 || len(c.Slowest) != reportSlowest {
+		t.Fatalf("cache report = %+v", c)
+	}
+	for i := 1; i < len(c.Slowest); i++ {
+		if c.Slowest[i].Duration > c.Slowest[i-1].Duration {
+			t.Fatalf("slowest not sorted: %+v", c.Slowest)
+		}
+	}
+
+	var buf bytes.Buffer
+	slog.New(slog.NewTextHandler(&buf, nil)).Info("request", slog.Any("ctxcache", r))
+	if !strings.Contains(buf.String(), "ctxcache.report.loads=7") {
+		t.Fatalf("log = %s", buf.String())
+	}
+}
+
+func TestSlowLoads(t *testing.T) {
+	var s slowLoads
+	for _, ms := range []int{1, 3, 2, 3, 0, 4, 5, 6} {
+		s.record(time.Duration(ms)*time.Millisecond, func() string { return strconv.Itoa(ms) })
+	}
+	var got []string
+	for _, l := range s.list() {
+		got = append(got, l.Key)
+	}
+	if strings.Join(got, ",") != "6,5,4,3,3" {
+		t.Fatalf("slowest = %v", got)
+	}
+}
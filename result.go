@@ -0,0 +1,88 @@
+package ctxcache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// Result holds the outcome of a call that can fail, so that both values and
+// errors can be cached by a CacheFunc.
+type Result[V any] struct {
+	Value V
+	Err   error
+}
+
+// Ok returns a successful Result holding v.
+func Ok[V any](v V) Result[V] {
+	return Result[V]{Value: v}
+}
+
+// Err returns a failed Result holding err.
+func Err[V any](err error) Result[V] {
+	return Result[V]{Err: err}
+}
+
+// Unwrap returns the value and the error of r.
+func (r Result[V]) Unwrap() (V, error) {
+	return r.Value, r.Err
+}
+
+type resultJSON[V any] struct {
+	Value V      `json:"value"`
+	Error string `json:"error,omitempty"`
+}
+
+// MarshalJSON encodes r as {"value": ...} or {"value": ..., "error": "..."},
+// so Results can go through the JSON codec of external stores.
+func (r Result[V]) MarshalJSON() ([]byte, error) {
+	rj := resultJSON[V]{Value: r.Value}
+	if r.Err != nil {
+		rj.Error = r.Err.Error()
+	}
+	return json.Marshal(rj)
+}
+
+// UnmarshalJSON decodes the encoding of MarshalJSON. Errors are restored by
+// message only, so errors.Is no longer matches their original sentinel.
+func (r *Result[V]) UnmarshalJSON(data []byte) error {
+	var rj resultJSON[V]
+	if err := json.Unmarshal(data, &rj); err != nil {
+		return err
+	}
+	r.Value, r.Err = rj.Value, nil
+	if rj.Error != "" {
+		r.Err = errors.New(rj.Error)
+	}
+	return nil
+}
+
+// WrapE adapts f to a CacheFunc whose results, errors included, are cached:
+//
+//	ctx = ctxcache.WithCache(ctx, "user", ctxcache.WrapE(loadUser))
+func WrapE[K comparable, V any](f func(K) (V, error)) CacheFunc[K, Result[V]] {
+	return func(k K) Result[V] {
+		v, err := f(k)
+		return Result[V]{Value: v, Err: err}
+	}
+}
+
+// WrapCtxE adapts f to a CacheFuncE whose results, errors included, are
+// cached, unlike with f itself.
+func WrapCtxE[K comparable, V any](f func(context.Context, K) (V, error)) CacheFuncE[K, Result[V]] {
+	return func(ctx context.Context, k K) (Result[V], error) {
+		v, err := f(ctx, k)
+		return Result[V]{Value: v, Err: err}, nil
+	}
+}
+
+// UnwrapE turns a function returning Results, such as the one returned by
+// FromContext for a WrapE loader, back into one returning errors:
+//
+//	get, _ := ctxcache.FromContext(ctx, "user", ctxcache.WrapE(loadUser))
+//	user, err := ctxcache.UnwrapE(get)(id)
+func UnwrapE[K comparable, V any](f func(K) Result[V]) func(K) (V, error) {
+	return func(k K) (V, error) {
+		return f(k).Unwrap()
+	}
+}
@@ -0,0 +1,59 @@
+package ctxcache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestWrapE(t *testing.T) {
+	errNegative := errors.New("negative")
+	loads := 0
+	load := func(n int) (int, error) {
+		loads++
+		if n < 0 {
+			return 0, errNegative
+		}
+		return n * 2, nil
+	}
+	ctx := WithCache(context.Background(), FuncID("wrap"), WrapE(load))
+	get, _ := FromContext(ctx, FuncID("wrap"), WrapE(load))
+	f := UnwrapE(get)
+	for i := 0; i < 2; i++ {
+		if v, err := f(2); v != 4 || err != nil {
+			t.Fatalf("f(2) = %d, %v", v, err)
+		}
+		if _, err := f(-1); !errors.Is(err, errNegative) {
+			t.Fatalf("f(-1) err = %v", err)
+		}
+	}
+	if loads != 2 {
+		t.Fatalf("loads = %d, want 2", loads)
+	}
+
+	loadCtx := func(_ context.Context, n int) (int, error) { return load(n) }
+	ctx = WithCacheE(context.Background(), FuncID("wrap_ctx"), WrapCtxE(loadCtx))
+	getE, _ := FromContextE(ctx, FuncID("wrap_ctx"), WrapCtxE(loadCtx))
+	getE(-1)
+	if r, err := getE(-1); err != nil || !errors.Is(r.Err, errNegative) || loads != 3 {
+		t.Fatalf("getE(-1) = %+v, %v after %d loads", r, err, loads)
+	}
+}
+
+func TestResultJSON(t *testing.T) {
+	for _, r := range []Result[int]{Ok(3), Err[int](errors.New("boom"))} {
+		data, err := json.Marshal(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got Result[int]
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+		v, gotErr := got.Unwrap()
+		if v != r.Value || (gotErr == nil) != (r.Err == nil) || gotErr != nil && gotErr.Error() != r.Err.Error() {
+			t.Fatalf("%s decoded to %+v", data, got)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package ctxcache
+
+import (
+	"context"
+	"math/rand/v2"
+)
+
+// SampleRate makes only a p fraction of the calls go through the cache; the
+// others call the loader without reading or storing entries. Bypassed calls
+// are subject to the same quotas, wrappers and Fallback as misses, and are
+// counted apart in Stats.Bypassed and BypassTime, so a cache can be rolled
+// out gradually while comparing latencies.
+func SampleRate(p float64) Option {
+	return func(o *options) {
+		o.sampling = true
+		o.sampleRate = p
+	}
+}
+
+// Enabled evaluates enabled on every call and bypasses the cache like
+// SampleRate when it returns false, so a feature flag can turn caching off
+// at runtime. Entries cached before are kept for when it is turned back on.
+func Enabled(enabled func(ctx context.Context) bool) Option {
+	return func(o *options) {
+		o.enabled = enabled
+	}
+}
+
+// loadBypassed calls the loader for a call bypassing the cache.
+func (c *cache[K, V]) loadBypassed(ctx context.Context, k K) (V, error) {
+	if err := c.reserveLoad(ctx); err != nil {
+		var zero V
+		return c.fallback(k, zero, err)
+	}
+	v, d, err := c.invoke(ctx, k)
+	c.countBypass(d)
+	if err != nil {
+		return c.fallback(k, v, err)
+	}
+	return v, nil
+}
+
+// bypass reports whether a call should skip the cache.
+func (c *cache[K, V]) bypass(ctx context.Context) bool {
+	if c.opts.enabled != nil && !c.opts.enabled(ctx) {
+		return true
+	}
+	return c.opts.sampling && rand.Float64() >= c.opts.sampleRate
+}
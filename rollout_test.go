@@ -0,0 +1,64 @@
+package ctxcache
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSampleRate(t *testing.T) {
+	for _, tc := range []struct {
+		rate  float64
+		calls int
+	}{{0, 10}, {1, 1}} {
+		calls := 0
+		load := func(n int) int {
+			calls++
+			return n
+		}
+		ctx := WithCache(context.Background(), FuncID("sample"), load, SampleRate(tc.rate))
+		f, _ := FromContext(ctx, FuncID("sample"), load)
+		for i := 0; i < 10; i++ {
+			f(1)
+		}
+		if calls != tc.calls {
+			t.Fatalf("rate %v: calls = %d, want %d", tc.rate, calls, tc.calls)
+		}
+		s, _ := GetStats(ctx, FuncID("sample"))
+		if s.Bypassed+s.Hits+s.Loads != 10 || s.Bypassed != uint64(10-10*tc.rate) {
+			t.Fatalf("rate %v: stats = %+v", tc.rate, s)
+		}
+	}
+}
+
+func TestSampleRateGuarded(t *testing.T) {
+	load := func(_ context.Context, n int) (int, error) { return n, nil }
+	ctx := WithCacheE(context.Background(), FuncID("sample"), load, SampleRate(0), MaxLoads(1), Fallback(func(int) int { return -1 }))
+	f, _ := FromContextE(ctx, FuncID("sample"), load)
+	if v, err := f(1); v != 1 || err != nil {
+		t.Fatalf("f(1) = %d, %v", v, err)
+	}
+	if v, err := f(2); v != -1 || err != nil {
+		t.Fatalf("f(2) over quota = %d, %v, want the fallback", v, err)
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	calls := 0
+	load := func(n int) int {
+		calls++
+		return n
+	}
+	on := true
+	ctx := WithCache(context.Background(), FuncID("toggle"), load, Enabled(func(context.Context) bool { return on }))
+	f, _ := FromContext(ctx, FuncID("toggle"), load)
+	f(1)
+	f(1)
+	on = false
+	f(1)
+	f(1)
+	on = true
+	f(1)
+	if calls != 3 {
+		t.Fatalf("calls = %d, want 3", calls)
+	}
+}
@@ -0,0 +1,69 @@
+package ctxcache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sync"
+)
+
+// SeedFS fills the cache at install with the values of the file
+// "<FuncID>.json" of fsys, a JSON object mapping keys to values, such as
+// fixtures embedded with //go:embed. Seeded values are returned without
+// calling the loader. A missing file seeds nothing; an unreadable or
+// malformed one panics at install, like a mistyped option.
+//
+// Each file is parsed once per Option returned by SeedFS, on its first
+// install, so reuse the Option across requests. The parsed values are then
+// shared by every install and must not be modified; see CloneOnGet.
+func SeedFS(fsys fs.FS) Option {
+	s := &seed{fsys: fsys}
+	return func(o *options) {
+		o.seed = s
+	}
+}
+
+// seed holds the files of a SeedFS parsed so far, by FuncID.
+type seed struct {
+	fsys   fs.FS
+	parsed sync.Map
+}
+
+// seedValues returns the values of the file of id, parsing it on first use.
+func seedValues[K comparable, V any](s *seed, id FuncID) map[K]V {
+	if values, ok := s.parsed.Load(id); ok {
+		if values, ok := values.(map[K]V); ok {
+			return values
+		}
+	}
+	data, err := fs.ReadFile(s.fsys, string(id)+".json")
+	var values map[K]V
+	if errors.Is(err, fs.ErrNotExist) {
+		s.parsed.Store(id, values)
+		return nil
+	}
+	if err == nil {
+		err = json.Unmarshal(data, &values)
+	}
+	if err != nil {
+		panic(fmt.Sprintf("ctxcache: SeedFS option of %q: %v", id, err))
+	}
+	s.parsed.Store(id, values)
+	return values
+}
+
+func (c *cache[K, V]) seedFS(ctx context.Context, s *seed) {
+	values := seedValues[K, V](s, c.id)
+	if len(values) == 0 {
+		return
+	}
+	var evicted []K
+	c.lock.Lock()
+	for k, v := range values {
+		evicted = append(evicted, c.insert(k, v, nil, versionOf(ctx), 0)...)
+	}
+	c.lock.Unlock()
+	c.evicted(ctx, evicted)
+}
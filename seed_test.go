@@ -0,0 +1,66 @@
+package ctxcache
+
+import (
+	"context"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func TestSeedFS(t *testing.T) {
+	fixtures := fstest.MapFS{
+		"square.json": {Data: []byte(`{"2": 4, "3": 9}`)},
+		"bad.json":    {Data: []byte(`[]`)},
+	}
+	loads := 0
+	square := func(n int) int {
+		loads++
+		return n * n
+	}
+	ctx := WithCache(context.Background(), FuncID("square"), square, SeedFS(fixtures))
+	ctx = WithCache(ctx, FuncID("cube"), square, SeedFS(fixtures))
+	f, _ := FromContext(ctx, FuncID("square"), square)
+	if f(2) != 4 || f(3) != 9 || loads != 0 {
+		t.Fatalf("seeded values not served, loads = %d", loads)
+	}
+	g, _ := FromContext(ctx, FuncID("cube"), square)
+	g(2)
+	if loads != 1 {
+		t.Fatalf("loads = %d, want 1 for a cache without fixture", loads)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("malformed fixture did not panic")
+		}
+	}()
+	WithCache(ctx, FuncID("bad"), square, SeedFS(fixtures))
+}
+
+type countingFS struct {
+	fstest.MapFS
+	reads int
+}
+
+func (c *countingFS) ReadFile(name string) ([]byte, error) {
+	c.reads++
+	return fs.ReadFile(c.MapFS, name)
+}
+
+func TestSeedFSParsedOnce(t *testing.T) {
+	fixtures := &countingFS{MapFS: fstest.MapFS{"square.json": {Data: []byte(`{"2": 4}`)}}}
+	seed := SeedFS(fixtures)
+	square := func(n int) int { return n * n }
+	for range 3 {
+		ctx := WithCache(context.Background(), FuncID("square"), square, seed)
+		ctx = WithCache(ctx, FuncID("cube"), square, seed)
+		f, _ := FromContext(Namespace(ctx, "tenant-a"), FuncID("square"), square)
+		if s, _ := GetStats(Namespace(ctx, "tenant-a"), FuncID("square")); s.Entries != 1 {
+			t.Fatalf("partition not seeded: %+v", s)
+		}
+		f(2)
+	}
+	if fixtures.reads != 2 {
+		t.Fatalf("read fixtures %d times, want once per file", fixtures.reads)
+	}
+}
@@ -0,0 +1,28 @@
+package ctxcache
+
+import (
+	"iter"
+	"slices"
+)
+
+// SeqFunc adapts f, returning an iterator, to a CacheFunc caching the
+// values it yields, so the iterator is run once per key:
+//
+//	ctx = ctxcache.WithCache(ctx, "orders", ctxcache.SeqFunc(scanOrders))
+func SeqFunc[K comparable, V any](f func(K) iter.Seq[V]) CacheFunc[K, []V] {
+	return func(k K) []V {
+		return slices.Collect(f(k))
+	}
+}
+
+// Seq turns a function returning slices, such as the one returned by
+// FromContext for a SeqFunc loader, back into one returning iterators, which
+// replay the cached values on every scan:
+//
+//	get, _ := ctxcache.FromContext(ctx, "orders", ctxcache.SeqFunc(scanOrders))
+//	for order := range ctxcache.Seq(get)(userID) {
+func Seq[K comparable, V any](f func(K) []V) func(K) iter.Seq[V] {
+	return func(k K) iter.Seq[V] {
+		return slices.Values(f(k))
+	}
+}
@@ -0,0 +1,38 @@
+package ctxcache
+
+import (
+	"context"
+	"iter"
+	"slices"
+	"testing"
+)
+
+func TestSeq(t *testing.T) {
+	scans := 0
+	upTo := func(n int) iter.Seq[int] {
+		return func(yield func(int) bool) {
+			scans++
+			for i := range n {
+				if !yield(i) {
+					return
+				}
+			}
+		}
+	}
+	ctx := WithCache(context.Background(), FuncID("upTo"), SeqFunc(upTo))
+	get, _ := FromContext(ctx, FuncID("upTo"), SeqFunc(upTo))
+	f := Seq(get)
+	for range 3 {
+		if got := slices.Collect(f(3)); !slices.Equal(got, []int{0, 1, 2}) {
+			t.Fatalf("f(3) = %v", got)
+		}
+	}
+	for v := range f(3) {
+		if v == 1 {
+			break
+		}
+	}
+	if scans != 1 {
+		t.Fatalf("scans = %d, want 1", scans)
+	}
+}
@@ -0,0 +1,41 @@
+package ctxcache
+
+import (
+	"context"
+	"math/rand/v2"
+	"reflect"
+	"time"
+)
+
+// shadowTimeout bounds the shadow loads.
+const shadowTimeout = 30 * time.Second
+
+// Shadow calls the loader again on a rate fraction of the hits and passes
+// the cached and fresh values to diverged when they are not deeply equal,
+// to check that caching a function is safe before relying on it. The
+// cached value is returned right away: shadow loads run in the background,
+// in a context detached from the caller's cancellation and bounded to 30
+// seconds. Failed shadow loads are ignored and shadow loads are not counted
+// in the stats.
+func Shadow[K comparable, V any](rate float64, diverged func(ctx context.Context, k K, cached, fresh V)) Option {
+	return func(o *options) {
+		o.shadowRate = rate
+		o.shadow = diverged
+	}
+}
+
+func (c *cache[K, V]) shadow(ctx context.Context, k K, cached V) {
+	if c.shadowFn == nil || rand.Float64() >= c.opts.shadowRate {
+		return
+	}
+	loader := c.currentLoader()
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shadowTimeout)
+		defer cancel()
+		fresh, err := loader(ctx, k)
+		if err != nil || reflect.DeepEqual(cached, fresh) {
+			return
+		}
+		c.shadowFn(ctx, k, cached, fresh)
+	}()
+}
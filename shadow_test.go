@@ -0,0 +1,46 @@
+package ctxcache
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestShadow(t *testing.T) {
+	var calls atomic.Int32
+	load := func(n int) int {
+		return n + int(calls.Add(1))
+	}
+	diverged := make(chan [3]int, 1)
+	report := func(ctx context.Context, k int, cached, fresh int) {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("shadow load without deadline")
+		}
+		diverged <- [3]int{k, cached, fresh}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = WithCache(ctx, FuncID("shadow"), load, Shadow(1, report))
+	f, _ := FromContext(ctx, FuncID("shadow"), load)
+
+	if f(1) != 2 || f(1) != 2 {
+		t.Fatal("cached value not returned")
+	}
+	cancel()
+	select {
+	case d := <-diverged:
+		if d != [3]int{1, 2, 3} {
+			t.Fatalf("diverged = %v", d)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("divergence not reported")
+	}
+
+	ctx = WithCache(context.Background(), FuncID("shadow"), load, Shadow(0, report))
+	f, _ = FromContext(ctx, FuncID("shadow"), load)
+	f(1)
+	f(1)
+	if n := calls.Load(); n != 3 {
+		t.Fatalf("shadow load with rate 0: calls = %d", n)
+	}
+}
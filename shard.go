@@ -0,0 +1,52 @@
+package ctxcache
+
+import (
+	"fmt"
+	"hash/maphash"
+	"reflect"
+	"unsafe"
+)
+
+// Shards splits a Shared into n shards, each with its own lock, so
+// concurrent requests contend less. maxEntries is split evenly between the
+// shards, each evicting its own least recently used entries.
+func Shards(n int) SharedOption {
+	return func(o *sharedOptions) {
+		o.shards = n
+	}
+}
+
+// ShardHash sets the hash picking the shard of a key, to spread skewed key
+// distributions evenly. K must be the key type of the Shared, or NewShared
+// panics. By default integer and string keys are hashed directly, and other
+// keys through fmt.
+func ShardHash[K comparable](hash func(K) uint64) SharedOption {
+	return func(o *sharedOptions) {
+		o.hash = hash
+	}
+}
+
+func (s *Shared[K, V]) shard(key K) *sharedShard[K, V] {
+	if len(s.shards) == 1 {
+		return s.shards[0]
+	}
+	return s.shards[s.hash(key)%uint64(len(s.shards))]
+}
+
+// shardHash returns the ShardHash option hash, or a default hash of K.
+func shardHash[K comparable](hash any) func(K) uint64 {
+	if hash != nil {
+		h, ok := hash.(func(K) uint64)
+		if !ok {
+			panic(fmt.Sprintf("ctxcache: ShardHash option has type %T, want %T", hash, h))
+		}
+		return h
+	}
+	if h := intHash[K](); h != nil {
+		return func(k K) uint64 { return h(k) * 0x9e3779b97f4a7c15 }
+	}
+	if reflect.TypeFor[K]().Kind() == reflect.String {
+		return func(k K) uint64 { return maphash.String(hashSeed, *(*string)(unsafe.Pointer(&k))) }
+	}
+	return func(k K) uint64 { return maphash.String(hashSeed, fmt.Sprint(k)) }
+}
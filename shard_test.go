@@ -0,0 +1,45 @@
+package ctxcache
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestShards(t *testing.T) {
+	ctx := context.Background()
+	s := NewShared[int, int](8, Shards(4))
+	var wg sync.WaitGroup
+	for g := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 100 {
+				s.Set(ctx, g*100+i, i)
+				s.Get(ctx, g*100+i)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := s.Len(); n == 0 || n > 8 {
+		t.Fatalf("Len = %d, want at most 8", n)
+	}
+
+	skewed := NewShared[string, int](8, Shards(4), ShardHash(func(string) uint64 { return 0 }))
+	for _, k := range []string{"a", "b", "c", "d"} {
+		skewed.Set(ctx, k, 1)
+	}
+	if skewed.Len() != 2 {
+		t.Fatalf("Len = %d, want the 2 entries of the only shard used", skewed.Len())
+	}
+	if _, ok, _ := skewed.Get(ctx, "d"); !ok {
+		t.Fatal("most recent entry evicted")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("mistyped ShardHash did not panic")
+		}
+	}()
+	NewShared[int, int](0, Shards(2), ShardHash(func(string) uint64 { return 0 }))
+}
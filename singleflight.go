@@ -0,0 +1,32 @@
+package ctxcache
+
+import (
+	"context"
+	"fmt"
+)
+
+// Group deduplicates concurrent calls sharing a key across the process.
+// *singleflight.Group from golang.org/x/sync/singleflight implements it.
+type Group interface {
+	Do(key string, fn func() (any, error)) (v any, err error, shared bool)
+}
+
+// Singleflight wraps f so that concurrent calls for the same key, from any
+// context of the process, share one call through g, while the caches
+// installed per request still memoize the result:
+//
+//	var group singleflight.Group
+//	loadUser := ctxcache.Singleflight(&group, "user", fetchUser)
+//	ctx = ctxcache.WithCacheE(ctx, "user", loadUser)
+//
+// Keys are formatted as id:fmt.Sprint(k) in g. The shared call runs with the
+// context of the caller that started it.
+func Singleflight[K comparable, V any](g Group, id FuncID, f CacheFuncE[K, V]) CacheFuncE[K, V] {
+	return func(ctx context.Context, k K) (V, error) {
+		v, err, _ := g.Do(fmt.Sprintf("%s:%v", id, k), func() (any, error) {
+			return f(ctx, k)
+		})
+		r, _ := v.(V)
+		return r, err
+	}
+}
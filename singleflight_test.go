@@ -0,0 +1,60 @@
+package ctxcache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flightGroup is a minimal Group like singleflight.Group.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*call[any]
+}
+
+func (g *flightGroup) Do(key string, fn func() (any, error)) (any, error, bool) {
+	g.mu.Lock()
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		<-c.done
+		return c.v, c.err, true
+	}
+	c := &call[any]{done: make(chan struct{})}
+	g.calls[key] = c
+	g.mu.Unlock()
+	c.v, c.err = fn()
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	close(c.done)
+	return c.v, c.err, false
+}
+
+func TestSingleflight(t *testing.T) {
+	var calls atomic.Int64
+	fetch := func(_ context.Context, n int) (int, error) {
+		calls.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		return n * 2, nil
+	}
+	load := Singleflight(&flightGroup{calls: map[string]*call[any]{}}, FuncID("flight"), fetch)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx := WithCacheE(context.Background(), FuncID("flight"), load)
+			f, _ := FromContextE(ctx, FuncID("flight"), load)
+			if v, err := f(3); v != 6 || err != nil {
+				t.Errorf("f(3) = %d, %v", v, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("fetch called %d times, want 1", n)
+	}
+}
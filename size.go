@@ -0,0 +1,101 @@
+package ctxcache
+
+import (
+	"context"
+	"reflect"
+	"unsafe"
+)
+
+// EstimatedBytes estimates the memory held by the entries of the cache
+// installed in ctx under ctxKey. It sums the costs of MaxCost when a cost
+// function is set, assuming costs are sizes, and otherwise walks keys and
+// values with reflection. The walk is approximate and takes the cache lock,
+// so it is meant for monitoring rather than hot paths.
+func EstimatedBytes(ctx context.Context, ctxKey FuncID) (int64, bool) {
+	c, ok := cacheValue(ctx, ctxKey).(anyCache)
+	if !ok {
+		return 0, false
+	}
+	return c.estimatedBytes(), true
+}
+
+func (c *cache[K, V]) estimatedBytes() int64 {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	var n int64
+	for k, e := range c.data.all() {
+		if c.costFn != nil {
+			n += c.costFn(k, e.value)
+			continue
+		}
+		seen := make(map[uintptr]bool)
+		n += int64(unsafe.Sizeof(*e)) +
+			sizeOf(reflect.ValueOf(&e.key).Elem(), seen) - int64(unsafe.Sizeof(e.key)) +
+			sizeOf(reflect.ValueOf(&e.value).Elem(), seen) - int64(unsafe.Sizeof(e.value))
+	}
+	return n
+}
+
+// sizeOf estimates the bytes held by v, including what it points to.
+// Memory reachable twice is counted once.
+func sizeOf(v reflect.Value, seen map[uintptr]bool) int64 {
+	n := int64(v.Type().Size())
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() || seen[v.Pointer()] {
+			return n
+		}
+		seen[v.Pointer()] = true
+		return n + sizeOf(v.Elem(), seen)
+	case reflect.String:
+		return n + int64(v.Len())
+	case reflect.Slice:
+		if v.IsNil() || seen[v.Pointer()] {
+			return n
+		}
+		seen[v.Pointer()] = true
+		n += int64(v.Cap()-v.Len()) * int64(v.Type().Elem().Size())
+		for i := 0; i < v.Len(); i++ {
+			n += sizeOf(v.Index(i), seen)
+		}
+		return n
+	case reflect.Array:
+		n = 0
+		for i := 0; i < v.Len(); i++ {
+			n += sizeOf(v.Index(i), seen)
+		}
+		return n
+	case reflect.Map:
+		if v.IsNil() || seen[v.Pointer()] {
+			return n
+		}
+		seen[v.Pointer()] = true
+		iter := v.MapRange()
+		for iter.Next() {
+			n += sizeOf(iter.Key(), seen) + sizeOf(iter.Value(), seen)
+		}
+		return n
+	case reflect.Interface:
+		if v.IsNil() {
+			return n
+		}
+		return n + sizeOf(v.Elem(), seen)
+	case reflect.Struct:
+		n = 0
+		for i := 0; i < v.NumField(); i++ {
+			n += sizeOf(v.Field(i), seen)
+		}
+		return n + int64(v.Type().Size()) - structFieldsSize(v.Type())
+	}
+	return n
+}
+
+// structFieldsSize returns the sum of the field sizes of t, so padding can
+// be accounted for.
+func structFieldsSize(t reflect.Type) int64 {
+	var n int64
+	for i := 0; i < t.NumField(); i++ {
+		n += int64(t.Field(i).Type.Size())
+	}
+	return n
+}
@@ -0,0 +1,44 @@
+package ctxcache
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSizeOf(t *testing.T) {
+	type user struct {
+		Name string
+		Tags []string
+	}
+	u := &user{Name: strings.Repeat("x", 100), Tags: []string{"a", "b"}}
+	n := sizeOf(reflect.ValueOf(u), map[uintptr]bool{})
+	// pointer + struct + name bytes + two string headers and bytes
+	want := int64(8 + 16 + 24 + 100 + 2*16 + 2)
+	if n != want {
+		t.Fatalf("sizeOf = %d, want %d", n, want)
+	}
+}
+
+func TestEstimatedBytes(t *testing.T) {
+	load := func(n int) string { return strings.Repeat("x", n) }
+	ctx := WithCache(context.Background(), FuncID("size"), load)
+	f, _ := FromContext(ctx, FuncID("size"), load)
+	f(1000)
+	small, _ := EstimatedBytes(ctx, FuncID("size"))
+	f(10000)
+	large, ok := EstimatedBytes(ctx, FuncID("size"))
+	if !ok || small < 1000 || large-small < 10000 {
+		t.Fatalf("estimated %d then %d bytes", small, large)
+	}
+
+	cost := func(_ int, s string) int64 { return int64(len(s)) }
+	ctx = WithCache(context.Background(), FuncID("size"), load, MaxCost(1<<20, cost))
+	f, _ = FromContext(ctx, FuncID("size"), load)
+	f(10)
+	f(20)
+	if n, _ := EstimatedBytes(ctx, FuncID("size")); n != 30 {
+		t.Fatalf("estimated %d bytes with a cost function", n)
+	}
+}
@@ -0,0 +1,174 @@
+package ctxcache
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stats are the counters of a single cache.
+type Stats struct {
+	Hits      uint64        `json:"hits"`
+	Misses    uint64        `json:"misses"`
+	Loads     uint64        `json:"loads"`
+	Evictions uint64        `json:"evictions"`
+	LoadTime  time.Duration `json:"load_time"`
+	Entries   int           `json:"entries"`
+	// Duplicates counts the loads of keys whose value was stored by a
+	// concurrent load meanwhile, which StrictOnce prevents.
+	Duplicates uint64 `json:"duplicates"`
+	// Errors counts the loads that failed.
+	Errors uint64 `json:"errors"`
+	// Bypassed counts the calls bypassing the cache with SampleRate or
+	// Enabled, and BypassTime the time spent in their loader.
+	Bypassed   uint64        `json:"bypassed"`
+	BypassTime time.Duration `json:"bypass_time"`
+	// LoadP50, LoadP90 and LoadP99 estimate quantiles of the load
+	// durations with LoadQuantiles, and are zero otherwise.
+	LoadP50 time.Duration `json:"load_p50,omitempty"`
+	LoadP90 time.Duration `json:"load_p90,omitempty"`
+	LoadP99 time.Duration `json:"load_p99,omitempty"`
+}
+
+type counters struct {
+	// hits and misses are striped, being written on every call.
+	hits       adder
+	misses     adder
+	loads      atomic.Uint64
+	evictions  atomic.Uint64
+	loadTime   atomic.Int64
+	duplicates atomic.Uint64
+	errors     atomic.Uint64
+	bypassed   atomic.Uint64
+	bypassTime atomic.Int64
+	quantiles  atomic.Pointer[quantiles]
+	// entries counts the entries held, in process counters only.
+	entries atomic.Int64
+}
+
+// GetStats returns the stats of the cache installed in ctx under ctxKey.
+func GetStats(ctx context.Context, ctxKey FuncID) (Stats, bool) {
+	c, ok := cacheValue(ctx, ctxKey).(anyCache)
+	if !ok {
+		return Stats{}, false
+	}
+	return c.stats(), true
+}
+
+func (cs *counters) snapshot() Stats {
+	s := Stats{
+		Hits:       cs.hits.Load(),
+		Misses:     cs.misses.Load(),
+		Loads:      cs.loads.Load(),
+		Evictions:  cs.evictions.Load(),
+		LoadTime:   time.Duration(cs.loadTime.Load()),
+		Duplicates: cs.duplicates.Load(),
+		Errors:     cs.errors.Load(),
+		Bypassed:   cs.bypassed.Load(),
+		BypassTime: time.Duration(cs.bypassTime.Load()),
+		Entries:    int(cs.entries.Load()),
+	}
+	if q := cs.quantiles.Load(); q != nil {
+		s.LoadP50, s.LoadP90, s.LoadP99 = q.values()
+	}
+	return s
+}
+
+// processStats aggregates the counters of every cache by FuncID.
+var processStats sync.Map
+
+func processCounters(id FuncID) *counters {
+	if cs, ok := processStats.Load(id); ok {
+		return cs.(*counters)
+	}
+	cs, _ := processStats.LoadOrStore(id, &counters{})
+	return cs.(*counters)
+}
+
+// ProcessStats returns the counters of all caches installed so far in the
+// process, aggregated by FuncID. Entries counts the entries held by the
+// caches not yet released or garbage collected.
+func ProcessStats() map[FuncID]Stats {
+	stats := make(map[FuncID]Stats)
+	processStats.Range(func(id, cs any) bool {
+		stats[id.(FuncID)] = cs.(*counters).snapshot()
+		return true
+	})
+	return stats
+}
+
+// liveEntries counts the entries of a cache into its process counters. The
+// count is taken back once the cache is garbage collected, since the caches
+// of finished requests are usually dropped without being released. It does
+// not point to the cache, so that it is collected along with it.
+type liveEntries struct {
+	n       atomic.Int64
+	process *counters
+}
+
+func newLiveEntries(process *counters) *liveEntries {
+	l := &liveEntries{process: process}
+	runtime.SetFinalizer(l, func(l *liveEntries) {
+		l.process.entries.Add(-l.n.Load())
+	})
+	return l
+}
+
+func (l *liveEntries) add(n int64) {
+	l.n.Add(n)
+	l.process.entries.Add(n)
+}
+
+func (c *cache[K, V]) stats() Stats {
+	c.lock.RLock()
+	n := c.data.len()
+	c.lock.RUnlock()
+	s := c.counters.snapshot()
+	s.Entries = n
+	return s
+}
+
+func (c *cache[K, V]) countHit() {
+	c.counters.hits.Add(1)
+	c.process.hits.Add(1)
+}
+
+func (c *cache[K, V]) countMiss() {
+	c.counters.misses.Add(1)
+	c.process.misses.Add(1)
+}
+
+func (c *cache[K, V]) countLoad(d time.Duration) {
+	c.counters.loads.Add(1)
+	c.counters.loadTime.Add(int64(d))
+	c.process.loads.Add(1)
+	c.process.loadTime.Add(int64(d))
+	if q := c.counters.quantiles.Load(); q != nil {
+		q.add(d)
+		c.process.quantiles.Load().add(d)
+	}
+}
+
+func (c *cache[K, V]) countEviction() {
+	c.counters.evictions.Add(1)
+	c.process.evictions.Add(1)
+}
+
+func (c *cache[K, V]) countError() {
+	c.counters.errors.Add(1)
+	c.process.errors.Add(1)
+}
+
+func (c *cache[K, V]) countDuplicate() {
+	c.counters.duplicates.Add(1)
+	c.process.duplicates.Add(1)
+}
+
+func (c *cache[K, V]) countBypass(d time.Duration) {
+	c.counters.bypassed.Add(1)
+	c.counters.bypassTime.Add(int64(d))
+	c.process.bypassed.Add(1)
+	c.process.bypassTime.Add(int64(d))
+}
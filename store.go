@@ -0,0 +1,275 @@
+package ctxcache
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Store is a cache tier shared across contexts. It backs the per-context
+// cache when installed with WithStore.
+type Store[K comparable, V any] interface {
+	Get(ctx context.Context, key K) (V, bool, error)
+	Set(ctx context.Context, key K, value V) error
+	Delete(ctx context.Context, key K) error
+}
+
+// WithStore makes store the second tier of the cache: misses in the context
+// are looked up in store before the loader runs, and loaded values are
+// written back to it. Values read from store are kept in the context too, so
+// the context overlays store: evictions and writes in store by other
+// contexts do not change what the context has already seen.
+func WithStore[K comparable, V any](store Store[K, V]) Option {
+	return func(o *options) {
+		o.store = store
+	}
+}
+
+// tokenStore is implemented by stores that can reject writes of values
+// loaded before an invalidation.
+type tokenStore[K comparable, V any] interface {
+	token() uint64
+	setIfToken(ctx context.Context, key K, value V, token uint64, loadTime time.Duration) error
+}
+
+func optionAs[T any](v any, id FuncID, name string) T {
+	t, ok := v.(T)
+	if !ok {
+		panic(fmt.Sprintf("ctxcache: %s option of %q has type %T, want %T", name, id, v, t))
+	}
+	return t
+}
+
+// Shared is a bounded in-process Store evicting the least recently used
+// entries. It is safe to share between goroutines and requests.
+type Shared[K comparable, V any] struct {
+	shards []*sharedShard[K, V]
+	hash   func(K) uint64
+	opts   sharedOptions
+	gen    atomic.Uint64
+	now    func() time.Time
+}
+
+// sharedShard holds the entries of a Shared whose keys hash to it.
+type sharedShard[K comparable, V any] struct {
+	lock       sync.Mutex
+	maxEntries int
+	ll         *list.List
+	items      map[K]*list.Element
+}
+
+type sharedEntry[K comparable, V any] struct {
+	key    K
+	value  V
+	expiry time.Time
+	// delta is the time it took to load value, scaling early expiration.
+	delta time.Duration
+}
+
+// SharedOption configures a Shared.
+type SharedOption func(*sharedOptions)
+
+type sharedOptions struct {
+	ttl    time.Duration
+	beta   float64
+	shards int
+	hash   any
+}
+
+// ExpireAfter expires the entries of a Shared ttl after they are set.
+func ExpireAfter(ttl time.Duration) SharedOption {
+	return func(o *sharedOptions) {
+		o.ttl = ttl
+	}
+}
+
+// EarlyExpiration enables probabilistic early expiration (XFetch): each Get
+// may report a miss slightly before the entry expires, with a probability
+// growing as expiry nears and with the time the value took to load, so a
+// single caller refreshes a hot key before everyone misses at once. beta
+// scales the eagerness; 1 is the usual choice.
+func EarlyExpiration(beta float64) SharedOption {
+	return func(o *sharedOptions) {
+		o.beta = beta
+	}
+}
+
+// NewShared returns a Shared holding at most maxEntries values, or an
+// unbounded one if maxEntries <= 0.
+func NewShared[K comparable, V any](maxEntries int, opts ...SharedOption) *Shared[K, V] {
+	s := &Shared[K, V]{now: time.Now}
+	for _, opt := range opts {
+		opt(&s.opts)
+	}
+	n := max(s.opts.shards, 1)
+	if maxEntries > 0 {
+		maxEntries = (maxEntries + n - 1) / n
+	}
+	for range n {
+		s.shards = append(s.shards, &sharedShard[K, V]{
+			maxEntries: maxEntries,
+			ll:         list.New(),
+			items:      make(map[K]*list.Element),
+		})
+	}
+	if n > 1 {
+		s.hash = shardHash[K](s.opts.hash)
+	}
+	return s
+}
+
+func (s *Shared[K, V]) Get(_ context.Context, key K) (V, bool, error) {
+	sh := s.shard(key)
+	sh.lock.Lock()
+	defer sh.lock.Unlock()
+	var zero V
+	el, ok := sh.items[key]
+	if !ok {
+		return zero, false, nil
+	}
+	e := el.Value.(*sharedEntry[K, V])
+	if !e.expiry.IsZero() {
+		now := s.now()
+		if !now.Before(e.expiry) {
+			sh.removeElement(el)
+			return zero, false, nil
+		}
+		if s.expiresEarly(e, now) {
+			return zero, false, nil
+		}
+	}
+	sh.ll.MoveToFront(el)
+	return e.value, true, nil
+}
+
+// expiresEarly implements XFetch: it reports true when
+// now - delta*beta*ln(rand()) reaches the expiry of e.
+func (s *Shared[K, V]) expiresEarly(e *sharedEntry[K, V], now time.Time) bool {
+	if s.opts.beta <= 0 || e.delta <= 0 {
+		return false
+	}
+	gap := -float64(e.delta) * s.opts.beta * math.Log(1-rand.Float64())
+	return !now.Add(time.Duration(gap)).Before(e.expiry)
+}
+
+func (s *Shared[K, V]) Set(_ context.Context, key K, value V) error {
+	sh := s.shard(key)
+	sh.lock.Lock()
+	defer sh.lock.Unlock()
+	s.set(sh, key, value, 0)
+	return nil
+}
+
+// set stores value under key in sh. sh.lock must be held.
+func (s *Shared[K, V]) set(sh *sharedShard[K, V], key K, value V, delta time.Duration) {
+	var expiry time.Time
+	if s.opts.ttl > 0 {
+		expiry = s.now().Add(s.opts.ttl)
+	}
+	if el, ok := sh.items[key]; ok {
+		sh.ll.MoveToFront(el)
+		e := el.Value.(*sharedEntry[K, V])
+		e.value, e.expiry, e.delta = value, expiry, delta
+		return
+	}
+	sh.items[key] = sh.ll.PushFront(&sharedEntry[K, V]{key: key, value: value, expiry: expiry, delta: delta})
+	if sh.maxEntries > 0 && sh.ll.Len() > sh.maxEntries {
+		sh.removeElement(sh.ll.Back())
+	}
+}
+
+func (sh *sharedShard[K, V]) removeElement(el *list.Element) {
+	sh.ll.Remove(el)
+	delete(sh.items, el.Value.(*sharedEntry[K, V]).key)
+}
+
+// Delete invalidates key. Loads that started before the call will not
+// write their result back.
+func (s *Shared[K, V]) Delete(_ context.Context, key K) error {
+	sh := s.shard(key)
+	sh.lock.Lock()
+	defer sh.lock.Unlock()
+	s.gen.Add(1)
+	if el, ok := sh.items[key]; ok {
+		sh.removeElement(el)
+	}
+	return nil
+}
+
+// Purge invalidates all entries.
+func (s *Shared[K, V]) Purge() {
+	s.gen.Add(1)
+	for _, sh := range s.shards {
+		sh.lock.Lock()
+		sh.ll.Init()
+		sh.items = make(map[K]*list.Element)
+		sh.lock.Unlock()
+	}
+}
+
+// Len returns the number of entries in s.
+func (s *Shared[K, V]) Len() int {
+	n := 0
+	for _, sh := range s.shards {
+		sh.lock.Lock()
+		n += sh.ll.Len()
+		sh.lock.Unlock()
+	}
+	return n
+}
+
+func (s *Shared[K, V]) token() uint64 {
+	return s.gen.Load()
+}
+
+func (s *Shared[K, V]) setIfToken(_ context.Context, key K, value V, token uint64, loadTime time.Duration) error {
+	sh := s.shard(key)
+	sh.lock.Lock()
+	defer sh.lock.Unlock()
+	if s.gen.Load() == token {
+		s.set(sh, key, value, loadTime)
+	}
+	return nil
+}
+
+func (c *cache[K, V]) storeGet(ctx context.Context, k K) (V, bool) {
+	if c.store == nil {
+		var zero V
+		return zero, false
+	}
+	v, ok, err := c.store.Get(ctx, k)
+	c.storeErr.Store(&err)
+	if err != nil {
+		c.logStoreError(ctx, k, err)
+		return v, false
+	}
+	return v, ok
+}
+
+func (c *cache[K, V]) storeToken() uint64 {
+	if ts, ok := c.store.(tokenStore[K, V]); ok {
+		return ts.token()
+	}
+	return 0
+}
+
+func (c *cache[K, V]) storeSet(ctx context.Context, k K, v V, token uint64, loadTime time.Duration) {
+	if c.store == nil {
+		return
+	}
+	var err error
+	if ts, ok := c.store.(tokenStore[K, V]); ok {
+		err = ts.setIfToken(ctx, k, v, token, loadTime)
+	} else {
+		err = c.store.Set(ctx, k, v)
+	}
+	c.storeErr.Store(&err)
+	if err != nil {
+		c.logStoreError(ctx, k, err)
+	}
+}
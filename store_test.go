@@ -0,0 +1,107 @@
+package ctxcache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWithStore(t *testing.T) {
+	shared := NewShared[int, int](2)
+	loads := 0
+	square := func(n int) int {
+		loads++
+		return n * n
+	}
+
+	for i := 0; i < 3; i++ {
+		ctx := WithCache(context.Background(), FuncID("square"), square, WithStore[int, int](shared))
+		f, _ := FromContext(ctx, FuncID("square"), square)
+		if got := f(3); got != 9 {
+			t.Fatalf("f(3) = %d", got)
+		}
+	}
+	if loads != 1 {
+		t.Fatalf("loads = %d, want 1", loads)
+	}
+
+	shared.Set(context.Background(), 1, 1)
+	shared.Set(context.Background(), 2, 4)
+	if _, ok, _ := shared.Get(context.Background(), 3); ok {
+		t.Fatal("least recently used entry not evicted")
+	}
+}
+
+func TestSharedInvalidationToken(t *testing.T) {
+	shared := NewShared[int, int](0)
+	token := shared.token()
+	shared.Delete(context.Background(), 1)
+	shared.setIfToken(context.Background(), 1, 1, token, 0)
+	if shared.Len() != 0 {
+		t.Fatal("stale load written after invalidation")
+	}
+}
+
+func TestWithStoreTypeMismatch(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+	WithCache(context.Background(), FuncID("f"), func(n int) int { return n }, WithStore[string, int](NewShared[string, int](0)))
+}
+
+func TestSharedExpiration(t *testing.T) {
+	now := time.Unix(0, 0)
+	shared := NewShared[int, int](0, ExpireAfter(time.Minute), EarlyExpiration(1))
+	shared.now = func() time.Time { return now }
+	shared.Set(context.Background(), 1, 1)
+	shared.setIfToken(context.Background(), 2, 2, shared.token(), time.Second)
+
+	now = now.Add(30 * time.Second)
+	if _, ok, _ := shared.Get(context.Background(), 1); !ok {
+		t.Fatal("entry expired early")
+	}
+
+	// with a 1s load time, a refresh 1ms before expiry is almost certain
+	now = now.Add(30*time.Second - time.Millisecond)
+	early := 0
+	for i := 0; i < 100; i++ {
+		if _, ok, _ := shared.Get(context.Background(), 2); !ok {
+			early++
+		}
+	}
+	if early == 0 || shared.Len() != 2 {
+		t.Fatalf("early = %d, len = %d", early, shared.Len())
+	}
+
+	now = now.Add(time.Millisecond)
+	if _, ok, _ := shared.Get(context.Background(), 1); ok || shared.Len() != 1 {
+		t.Fatal("expired entry returned")
+	}
+}
+
+func TestWithStoreOverlay(t *testing.T) {
+	shared := NewShared[int, int](0)
+	shared.Set(context.Background(), 1, 10)
+	loads := 0
+	square := func(n int) int {
+		loads++
+		return n * n
+	}
+	ctx := WithCache(context.Background(), FuncID("square"), square, WithStore[int, int](shared))
+	f, _ := FromContext(ctx, FuncID("square"), square)
+	if got := f(1); got != 10 {
+		t.Fatalf("f(1) = %d, want the stored 10", got)
+	}
+	f(2)
+
+	shared.Delete(context.Background(), 1)
+	shared.Set(context.Background(), 2, 0)
+	if f(1) != 10 || f(2) != 4 || loads != 1 {
+		t.Fatalf("f(1) = %d, f(2) = %d, loads = %d after store changes", f(1), f(2), loads)
+	}
+	if v, ok, _ := shared.Get(context.Background(), 2); !ok || v != 0 {
+		t.Fatalf("store overwritten by the context: %d, %v", v, ok)
+	}
+}
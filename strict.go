@@ -0,0 +1,98 @@
+package ctxcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// StrictOnce makes concurrent misses of a key share a single loader call,
+// so the loader runs once per key for as long as its result stays cached.
+// It suits loaders with side effects. A failed load is shared by the
+// callers waiting on it and retried by later calls, except that waiters
+// load again when it failed because the context of its caller was done.
+// Loads under different WithVersion tokens are not shared.
+func StrictOnce() Option {
+	return func(o *options) {
+		o.strictOnce = true
+	}
+}
+
+// ErrLoaderPanicked is returned to the callers waiting on a StrictOnce load
+// whose loader panicked. The caller running the loader sees the panic.
+var ErrLoaderPanicked = errors.New("ctxcache: loader panicked")
+
+// call is a load in progress for StrictOnce.
+type call[V any] struct {
+	done chan struct{}
+	v    V
+	err  error
+}
+
+// flight identifies a load in progress: loads of a key under different
+// version tokens are not shared.
+type flight[K comparable] struct {
+	key     K
+	version string
+}
+
+func (c *cache[K, V]) loadOnce(ctx context.Context, k K) (V, error) {
+	for {
+		v, err, retry := c.shareLoad(ctx, k)
+		if !retry {
+			return v, err
+		}
+	}
+}
+
+// shareLoad loads k, or waits for the load of k in progress. It reports
+// retry when that load failed only because the context of its caller was
+// done, while ctx is not.
+func (c *cache[K, V]) shareLoad(ctx context.Context, k K) (v V, err error, retry bool) {
+	version := versionOf(ctx)
+	c.lock.Lock()
+	if e, ok := c.data.load(k); ok && e.version() == version && !c.expired(e, time.Now().UnixNano()) {
+		if v, alive := e.get(); alive {
+			c.lock.Unlock()
+			if e.err() != nil {
+				v, err = c.fallback(k, v, e.err())
+			}
+			return v, err, false
+		}
+	}
+	id := flight[K]{k, version}
+	if cl, ok := c.inflight[id]; ok {
+		c.lock.Unlock()
+		select {
+		case <-cl.done:
+			canceled := errors.Is(cl.err, context.Canceled) || errors.Is(cl.err, context.DeadlineExceeded)
+			return cl.v, cl.err, canceled && ctx.Err() == nil
+		case <-ctx.Done():
+			var zero V
+			return zero, ctx.Err(), false
+		}
+	}
+	cl := &call[V]{done: make(chan struct{})}
+	if c.inflight == nil {
+		c.inflight = make(map[flight[K]]*call[V])
+	}
+	c.inflight[id] = cl
+	c.lock.Unlock()
+
+	defer func() {
+		r := recover()
+		if r != nil {
+			cl.err = fmt.Errorf("%w: %v", ErrLoaderPanicked, r)
+		}
+		c.lock.Lock()
+		delete(c.inflight, id)
+		c.lock.Unlock()
+		close(cl.done)
+		if r != nil {
+			panic(r)
+		}
+	}()
+	cl.v, cl.err = c.load(ctx, k)
+	return cl.v, cl.err, false
+}
@@ -0,0 +1,174 @@
+package ctxcache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStrictOnce(t *testing.T) {
+	var calls atomic.Int64
+	load := func(n int) int {
+		calls.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		return n
+	}
+	ctx := WithCache(context.Background(), FuncID("strict"), load, StrictOnce())
+	f, _ := FromContext(ctx, FuncID("strict"), load)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v := f(i % 2); v != i%2 {
+				t.Errorf("f(%d) = %d", i%2, v)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("loader called %d times, want 2", n)
+	}
+}
+
+func TestDuplicates(t *testing.T) {
+	release := make(chan struct{})
+	load := func(n int) int {
+		<-release
+		return n
+	}
+	ctx := WithCache(context.Background(), FuncID("duplicates"), load)
+	f, _ := FromContext(ctx, FuncID("duplicates"), load)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f(1)
+		}()
+	}
+	for s, _ := GetStats(ctx, FuncID("duplicates")); s.Misses < 2; s, _ = GetStats(ctx, FuncID("duplicates")) {
+		time.Sleep(time.Millisecond)
+	}
+	release <- struct{}{}
+	for s, _ := GetStats(ctx, FuncID("duplicates")); s.Entries == 0; s, _ = GetStats(ctx, FuncID("duplicates")) {
+		time.Sleep(time.Millisecond)
+	}
+	release <- struct{}{}
+	wg.Wait()
+	if s, _ := GetStats(ctx, FuncID("duplicates")); s.Loads != 2 || s.Duplicates != 1 {
+		t.Fatalf("stats = %+v", s)
+	}
+}
+
+// waitInflight waits until n loads of the StrictOnce cache in ctx are in
+// progress.
+func waitInflight(ctx context.Context, id FuncID, n int) {
+	c := cacheValue(ctx, id).(*cache[int, int])
+	for {
+		c.lock.RLock()
+		m := len(c.inflight)
+		c.lock.RUnlock()
+		if m == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestStrictOncePanic(t *testing.T) {
+	release := make(chan struct{})
+	load := func(ctx context.Context, n int) (int, error) {
+		<-release
+		panic("boom")
+	}
+	ctx := WithCacheE(context.Background(), FuncID("strict"), load, StrictOnce())
+	f, _ := FromContextE(ctx, FuncID("strict"), load)
+	leader := make(chan any)
+	go func() {
+		defer func() { leader <- recover() }()
+		f(1)
+	}()
+	waitInflight(ctx, FuncID("strict"), 1)
+	waiter := make(chan error)
+	go func() {
+		_, err := f(1)
+		waiter <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	if r := <-leader; r != "boom" {
+		t.Fatalf("leader recovered %v", r)
+	}
+	if err := <-waiter; !errors.Is(err, ErrLoaderPanicked) {
+		t.Fatalf("waiter got %v", err)
+	}
+}
+
+func TestStrictOnceVersions(t *testing.T) {
+	release := make(chan struct{})
+	load := func(ctx context.Context, n int) (int, error) {
+		<-release
+		if versionOf(ctx) == "v2" {
+			return 2, nil
+		}
+		return 1, nil
+	}
+	ctx := WithCacheE(context.Background(), FuncID("strict"), load, StrictOnce())
+	f, _ := FromContextE(WithVersion(ctx, "v1"), FuncID("strict"), load)
+	g, _ := FromContextE(WithVersion(ctx, "v2"), FuncID("strict"), load)
+	results := make(chan int, 2)
+	go func() {
+		v, _ := f(0)
+		results <- v
+	}()
+	waitInflight(ctx, FuncID("strict"), 1)
+	go func() {
+		v, _ := g(0)
+		results <- v
+	}()
+	waitInflight(ctx, FuncID("strict"), 2)
+	close(release)
+	if a, b := <-results, <-results; a+b != 3 {
+		t.Fatalf("results = %d, %d: load shared across versions", a, b)
+	}
+}
+
+func TestStrictOnceLeaderCanceled(t *testing.T) {
+	var calls atomic.Int64
+	load := func(ctx context.Context, n int) (int, error) {
+		if calls.Add(1) == 1 {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}
+		return n, nil
+	}
+	ctx := WithCacheE(context.Background(), FuncID("strict"), load, StrictOnce())
+	canceled, cancel := context.WithCancel(ctx)
+	f, _ := FromContextE(canceled, FuncID("strict"), load)
+	g, _ := FromContextE(ctx, FuncID("strict"), load)
+	leader := make(chan error)
+	go func() {
+		_, err := f(1)
+		leader <- err
+	}()
+	waitInflight(ctx, FuncID("strict"), 1)
+	waiter := make(chan error)
+	go func() {
+		_, err := g(1)
+		waiter <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	if err := <-leader; !errors.Is(err, context.Canceled) {
+		t.Fatalf("leader got %v", err)
+	}
+	if err := <-waiter; err != nil {
+		t.Fatalf("waiter got %v", err)
+	}
+}
@@ -0,0 +1,27 @@
+package ctxcache
+
+import "context"
+
+// SwapLoader replaces the loader of the cache installed in ctx under ctxKey
+// with f and returns the previous one. Cached entries are kept; only later
+// misses call f.
+func SwapLoader[K comparable, V any](ctx context.Context, ctxKey FuncID, f CacheFunc[K, V]) (CacheFunc[K, V], bool) {
+	c, ok := cacheValue(ctx, ctxKey).(*cache[K, V])
+	if !ok {
+		return nil, false
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	prev := c.loader
+	c.loader = plainLoader(f)
+	return func(k K) V {
+		v, _ := prev(ctx, k)
+		return v
+	}, true
+}
+
+func (c *cache[K, V]) currentLoader() CacheFuncE[K, V] {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.loader
+}
@@ -0,0 +1,25 @@
+package ctxcache
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSwapLoader(t *testing.T) {
+	double := func(n int) int { return n * 2 }
+	triple := func(n int) int { return n * 3 }
+	ctx := WithCache(context.Background(), FuncID("mul"), double)
+	f, _ := FromContext(ctx, FuncID("mul"), double)
+	f(1)
+
+	prev, ok := SwapLoader(ctx, FuncID("mul"), triple)
+	if !ok || prev(1) != 2 {
+		t.Fatal("SwapLoader did not return the previous loader")
+	}
+	if f(1) != 2 || f(2) != 6 {
+		t.Fatalf("f(1) = %d, f(2) = %d", f(1), f(2))
+	}
+	if _, ok := SwapLoader[string, int](ctx, FuncID("mul"), nil); ok {
+		t.Fatal("swapped a loader of mismatched type")
+	}
+}
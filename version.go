@@ -0,0 +1,21 @@
+package ctxcache
+
+import "context"
+
+type versionKey struct{}
+
+// WithVersion returns a context in which entries written under any other
+// version token are treated as misses. Changing the token, for example
+// after a bulk import, busts every cache in ctx without invalidating keys
+// one by one. Entries are written under the token of the context they are
+// loaded in. A Store set with WithStore does not record tokens, so it is not
+// read under one; loaded values are still written to it.
+func WithVersion(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, versionKey{}, token)
+}
+
+// versionOf returns the version token of ctx, empty if none.
+func versionOf(ctx context.Context) string {
+	token, _ := ctx.Value(versionKey{}).(string)
+	return token
+}
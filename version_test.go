@@ -0,0 +1,51 @@
+package ctxcache
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithVersion(t *testing.T) {
+	loads := 0
+	inc := func(n int) int {
+		loads++
+		return n + 1
+	}
+	ctx := WithCache(context.Background(), FuncID("inc"), inc)
+	v1 := WithVersion(ctx, "v1")
+	f, _ := FromContext(v1, FuncID("inc"), inc)
+	f(1)
+	f(1)
+	if loads != 1 {
+		t.Fatalf("loads = %d, want 1", loads)
+	}
+
+	v2 := WithVersion(ctx, "v2")
+	g, _ := FromContext(v2, FuncID("inc"), inc)
+	g(1)
+	g(1)
+	if loads != 2 {
+		t.Fatalf("loads = %d, want 2: v1 entry served under v2", loads)
+	}
+	f(1)
+	if loads != 3 {
+		t.Fatalf("loads = %d, want 3: v2 entry served under v1", loads)
+	}
+}
+
+func TestWithVersionStore(t *testing.T) {
+	loads := 0
+	inc := func(n int) int {
+		loads++
+		return n + loads
+	}
+	shared := NewShared[int, int](0)
+	f, _ := FromContext(WithVersion(WithCache(context.Background(), FuncID("inc"), inc, WithStore[int, int](shared)), "v1"), FuncID("inc"), inc)
+	if v := f(1); v != 2 {
+		t.Fatalf("f(1) = %d under v1", v)
+	}
+	g, _ := FromContext(WithVersion(WithCache(context.Background(), FuncID("inc"), inc, WithStore[int, int](shared)), "v2"), FuncID("inc"), inc)
+	if v := g(1); v != 3 || loads != 2 {
+		t.Fatalf("g(1) = %d under v2 after %d loads: v1 value read from the store", v, loads)
+	}
+}
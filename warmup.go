@@ -0,0 +1,55 @@
+package ctxcache
+
+import (
+	"context"
+	"sync"
+)
+
+// WarmUp loads keys into the cache installed in ctx under ctxKey, running
+// at most parallelism loads at once, or all of them if parallelism is not
+// positive. It returns the first error and cancels the context of the
+// loads not finished yet. It does nothing if no cache is installed.
+func WarmUp[K comparable, V any](ctx context.Context, ctxKey FuncID, keys []K, parallelism int) error {
+	c, ok := cacheValue(ctx, ctxKey).(*cache[K, V])
+	if !ok {
+		return nil
+	}
+	if parallelism <= 0 {
+		parallelism = len(keys)
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	sc := scopeOf(ctx)
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	var once sync.Once
+	var first error
+	for _, k := range keys {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if _, err := c.cacheLoader(ctx, sc, k); err != nil {
+				once.Do(func() {
+					first = err
+					cancel(err)
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	if first == nil {
+		return context.Cause(ctx)
+	}
+	return first
+}
@@ -0,0 +1,38 @@
+package ctxcache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWarmUp(t *testing.T) {
+	errNegative := errors.New("negative")
+	var running, peak atomic.Int64
+	load := func(_ context.Context, n int) (int, error) {
+		if r := running.Add(1); r > peak.Load() {
+			peak.Store(r)
+		}
+		defer running.Add(-1)
+		time.Sleep(time.Millisecond)
+		if n < 0 {
+			return 0, errNegative
+		}
+		return n, nil
+	}
+	ctx := WithCacheE(context.Background(), FuncID("warmup"), load)
+	if err := WarmUp[int, int](ctx, FuncID("warmup"), []int{1, 2, 3, 4, 5, 6}, 2); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := GetStats(ctx, FuncID("warmup")); s.Entries != 6 || peak.Load() > 2 {
+		t.Fatalf("stats = %+v, peak = %d", s, peak.Load())
+	}
+	if err := WarmUp[int, int](ctx, FuncID("warmup"), []int{7, -1, 8}, 1); !errors.Is(err, errNegative) {
+		t.Fatalf("err = %v", err)
+	}
+	if err := WarmUp[int, int](context.Background(), FuncID("warmup"), []int{1}, 1); err != nil {
+		t.Fatal(err)
+	}
+}
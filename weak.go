@@ -0,0 +1,20 @@
+//go:build go1.24
+
+package ctxcache
+
+import (
+	"unsafe"
+	"weak"
+)
+
+// weakRef is a weak pointer to the value of a WeakValues entry.
+type weakRef = weak.Pointer[byte]
+
+func makeWeakRef(p unsafe.Pointer) weakRef {
+	return weak.Make((*byte)(p))
+}
+
+// weakTarget returns the value pointed to by r, nil once collected.
+func weakTarget(r weakRef) unsafe.Pointer {
+	return unsafe.Pointer(r.Value())
+}
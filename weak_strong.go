@@ -0,0 +1,19 @@
+//go:build !go1.24
+
+package ctxcache
+
+import "unsafe"
+
+// weakRef holds the value of a WeakValues entry strongly, as weak pointers
+// need Go 1.24.
+type weakRef struct {
+	p unsafe.Pointer
+}
+
+func makeWeakRef(p unsafe.Pointer) weakRef {
+	return weakRef{p: p}
+}
+
+func weakTarget(r weakRef) unsafe.Pointer {
+	return r.p
+}
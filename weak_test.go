@@ -0,0 +1,40 @@
+//go:build go1.24
+
+package ctxcache
+
+import (
+	"context"
+	"runtime"
+	"testing"
+)
+
+func TestWeakValues(t *testing.T) {
+	type blob struct{ data [1 << 16]byte }
+	loads := 0
+	load := func(n int) *blob {
+		loads++
+		b := &blob{}
+		b.data[0] = byte(n)
+		return b
+	}
+	ctx := WithCache(context.Background(), FuncID("blob"), load, WeakValues())
+	f, _ := FromContext(ctx, FuncID("blob"), load)
+	held := f(1)
+	runtime.GC()
+	if f(1) != held || loads != 1 {
+		t.Fatalf("loads = %d, want the held value served", loads)
+	}
+	held = nil
+	runtime.GC()
+	if b := f(1); b.data[0] != 1 || loads != 2 {
+		t.Fatalf("loads = %d, want the reclaimed value reloaded", loads)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("WeakValues with non-pointer values did not panic")
+		}
+	}()
+	inc := func(n int) int { return n + 1 }
+	WithCache(context.Background(), FuncID("inc"), inc, WeakValues())
+}
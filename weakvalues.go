@@ -0,0 +1,38 @@
+package ctxcache
+
+import "unsafe"
+
+// WeakValues holds cached values through weak pointers, so the GC can
+// reclaim a large value once nothing else references it; the loader then
+// runs again the next time its key is requested. Values must be pointers,
+// or installing the cache panics. Built with Go before 1.24, values are
+// held strongly.
+func WeakValues() Option {
+	return func(o *options) {
+		o.weakValues = true
+	}
+}
+
+// holdWeakly moves the value of e, a pointer, to a weak reference.
+func (e *entry[K, V]) holdWeakly() {
+	p := *(*unsafe.Pointer)(unsafe.Pointer(&e.value))
+	if p == nil {
+		return
+	}
+	var zero V
+	x := e.extra()
+	x.weak, x.weakly, e.value = makeWeakRef(p), true, zero
+}
+
+// get returns the value of e, and false if it was reclaimed.
+func (e *entry[K, V]) get() (V, bool) {
+	if e.x == nil || !e.x.weakly {
+		return e.value, true
+	}
+	p := weakTarget(e.x.weak)
+	if p == nil {
+		var zero V
+		return zero, false
+	}
+	return *(*V)(unsafe.Pointer(&p)), true
+}
@@ -0,0 +1,49 @@
+package ctxcache
+
+import (
+	"context"
+	"time"
+)
+
+// start starts the background work of a cache installed in ctx, which also
+// serves its Namespace partitions.
+func (c *cache[K, V]) start(ctx context.Context) {
+	if c.opts.releaseOnDone {
+		context.AfterFunc(ctx, c.close)
+	}
+	if c.opts.sweepInterval > 0 {
+		c.startWorker(ctx, c.opts.sweepInterval, (*cache[K, V]).sweep)
+	}
+	if c.opts.refreshInterval > 0 {
+		c.startWorker(ctx, c.opts.refreshInterval, (*cache[K, V]).refreshAhead)
+	}
+}
+
+// startWorker calls fn on the cache and its partitions every interval until
+// ctx is done or the workers of the cache are stopped.
+func (c *cache[K, V]) startWorker(ctx context.Context, interval time.Duration, fn func(c *cache[K, V], ctx context.Context, now time.Time)) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-c.stopped:
+				return
+			case now := <-ticker.C:
+				fn(c, ctx, now)
+				c.eachSpace(func(s *cache[K, V]) {
+					fn(s, ctx, now)
+				})
+			}
+		}
+	}()
+}
+
+func (c *cache[K, V]) stopWorkers() {
+	c.eachSpace((*cache[K, V]).stopWorkers)
+	c.stopOnce.Do(func() {
+		close(c.stopped)
+	})
+}
@@ -0,0 +1,89 @@
+package ctxcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrNondeterministic is returned by loads of caches installed with
+// Nondeterministic in a Deterministic context.
+var ErrNondeterministic = errors.New("ctxcache: nondeterministic loader in deterministic context")
+
+// WorkflowState persists loader results in the state of a workflow, such as
+// the history of a Temporal or Cadence workflow, by FuncID and by key
+// formatted with fmt.Sprint.
+type WorkflowState interface {
+	// Load returns the result recorded for key of the cache id, if any.
+	Load(id FuncID, key string) (data []byte, ok bool)
+	// Store records the result of key of the cache id.
+	Store(id FuncID, key string, data []byte) error
+}
+
+type deterministicKey struct{}
+
+type determinism struct {
+	state WorkflowState
+	codec Codec
+}
+
+// workflowResult is a loader result as recorded in a WorkflowState.
+type workflowResult[V any] struct {
+	Value V
+	Err   string `json:",omitempty"`
+}
+
+// Deterministic returns a context in which loads of every cache are served
+// from state when it holds their result, and otherwise run once and have
+// their result, error included, serialized with codec and stored in state.
+// Replaying a workflow thus never re-executes loaders; replayed errors keep
+// their message only.
+func Deterministic(ctx context.Context, state WorkflowState, codec Codec) context.Context {
+	return context.WithValue(ctx, deterministicKey{}, &determinism{state: state, codec: codec})
+}
+
+// Nondeterministic marks the loader as unfit for workflows: in a
+// Deterministic context its loads fail with ErrNondeterministic, even when
+// state holds a result.
+func Nondeterministic() Option {
+	return func(o *options) {
+		o.nondeterministic = true
+	}
+}
+
+// deterministic wraps load to go through the WorkflowState of ctx, if any.
+func (c *cache[K, V]) deterministic(ctx context.Context, load CacheFuncE[K, V]) CacheFuncE[K, V] {
+	d, ok := ctx.Value(deterministicKey{}).(*determinism)
+	if !ok {
+		return load
+	}
+	return func(ctx context.Context, k K) (V, error) {
+		var res workflowResult[V]
+		if c.opts.nondeterministic {
+			return res.Value, fmt.Errorf("%w: %s", ErrNondeterministic, c.id)
+		}
+		key := c.recordKey(k)
+		if data, ok := d.state.Load(c.id, key); ok {
+			if err := d.codec.Unmarshal(data, &res); err != nil {
+				return res.Value, err
+			}
+			if res.Err != "" {
+				return res.Value, errors.New(res.Err)
+			}
+			return res.Value, nil
+		}
+		v, err := load(ctx, k)
+		res.Value = v
+		if err != nil {
+			res.Err = err.Error()
+		}
+		data, merr := d.codec.Marshal(res)
+		if merr != nil {
+			return v, merr
+		}
+		if serr := d.state.Store(c.id, key, data); serr != nil {
+			return v, serr
+		}
+		return v, err
+	}
+}
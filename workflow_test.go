@@ -0,0 +1,92 @@
+package ctxcache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type mapState map[string][]byte
+
+func (s mapState) Load(id FuncID, key string) ([]byte, bool) {
+	data, ok := s[string(id)+"/"+key]
+	return data, ok
+}
+
+func (s mapState) Store(id FuncID, key string, data []byte) error {
+	s[string(id)+"/"+key] = data
+	return nil
+}
+
+func TestDeterministic(t *testing.T) {
+	errOdd := errors.New("odd")
+	loads := 0
+	half := func(_ context.Context, n int) (int, error) {
+		loads++
+		if n%2 != 0 {
+			return 0, errOdd
+		}
+		return n / 2, nil
+	}
+	state := mapState{}
+	run := func() {
+		ctx := Deterministic(context.Background(), state, JSON)
+		ctx = WithCacheE(ctx, FuncID("half"), half)
+		f, _ := FromContextE(ctx, FuncID("half"), half)
+		if v, err := f(4); err != nil || v != 2 {
+			t.Fatalf("f(4) = %d, %v", v, err)
+		}
+		if _, err := f(3); err == nil || err.Error() != errOdd.Error() {
+			t.Fatalf("f(3) err = %v", err)
+		}
+	}
+	run()
+	run()
+	if loads != 2 || len(state) != 2 {
+		t.Fatalf("loads = %d, state = %d entries, want the replay to load nothing", loads, len(state))
+	}
+
+	ctx := Deterministic(context.Background(), state, JSON)
+	ctx = WithCacheE(ctx, FuncID("half"), half, Nondeterministic())
+	f, _ := FromContextE(ctx, FuncID("half"), half)
+	if _, err := f(4); !errors.Is(err, ErrNondeterministic) {
+		t.Fatalf("nondeterministic loader err = %v", err)
+	}
+}
+
+type countingState struct {
+	mapState
+	stores atomic.Int64
+}
+
+func (s *countingState) Store(id FuncID, key string, data []byte) error {
+	s.stores.Add(1)
+	return s.mapState.Store(id, key, data)
+}
+
+func TestDeterministicHedge(t *testing.T) {
+	var calls atomic.Int64
+	slow := make(chan struct{})
+	load := func(ctx context.Context, n int) (int, error) {
+		if calls.Add(1) == 1 {
+			<-ctx.Done()
+			close(slow)
+			return 0, ctx.Err()
+		}
+		return n, nil
+	}
+	state := &countingState{mapState: mapState{}}
+	ctx := Deterministic(context.Background(), state, JSON)
+	ctx = WithCacheE(ctx, FuncID("hedge"), load, Hedge(time.Millisecond))
+	f, _ := FromContextE(ctx, FuncID("hedge"), load)
+	if v, err := f(7); v != 7 || err != nil {
+		t.Fatalf("f(7) = %d, %v", v, err)
+	}
+	<-slow
+	time.Sleep(10 * time.Millisecond)
+	if n := state.stores.Load(); n != 1 {
+		t.Fatalf("%d results stored for one load", n)
+	}
+}